@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// multi-part RAR volumes other than the first one are extracted with it
+var rarVolume = regexp.MustCompile(`(?i)\.part0*(\d+)\.rar$`)
+
+func extractArchive(client *synoclient.Client, args []string) {
+	flags := newFlagSet("extract")
+	to := flags.String("to", "", "Destination folder, defaults to the archive folder")
+	password := flags.String("password", "", "Archive password")
+	overwrite := flags.Bool("overwrite", false, "Overwrite existing files")
+	keepDir := flags.Bool("keep-dir", true, "Keep folder structure of the archive")
+	subfolder := flags.Bool("subfolder", false, "Extract into a folder named after the archive")
+	list := flags.Bool("list", false, "List archive content only")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+
+	opts := synoclient.ExtractOptions{
+		Overwrite:       *overwrite,
+		KeepDir:         *keepDir,
+		CreateSubfolder: *subfolder,
+		Password:        *password,
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	archives, err := resolveArchives(client, positional[0])
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	var results []fileResult
+	if !*list {
+		defer func() { outputFileResults(results) }()
+	}
+
+	for _, archive := range archives {
+		if *list {
+			items, err := client.ListArchive(archive, *password)
+			if err != nil {
+				printError(err)
+				return
+			}
+			flat := flattenArchiveItems(items)
+			var rows [][]string
+			for _, item := range flat {
+				rows = append(rows, []string{item.Path, strconv.FormatInt(item.Size, 10), strconv.FormatBool(item.IsDir)})
+			}
+			printOutput(flat, []string{"Path", "Size", "Dir"}, rows, func() {
+				printArchiveItems(flat)
+			})
+			continue
+		}
+
+		destination := *to
+		if destination == "" {
+			destination = path.Dir(archive)
+		}
+
+		printMessage("Extracting %v\n", archive)
+		task, err := client.Extract(archive, destination, opts)
+		if err == nil {
+			err = waitFileTask(task)
+		}
+		results = append(results, newFileResult(archive, "extracted", destination, err))
+		if err != nil {
+			printError(err)
+			return
+		}
+		printMessage("Extracted to %v.\n", destination)
+	}
+}
+
+// resolveArchives returns the archive at path, archives within the folder
+// at path, or archives downloaded by a Download Station task
+func resolveArchives(client *synoclient.Client, arg string) ([]string, error) {
+	archivePath := arg
+	if strings.HasPrefix(arg, "dbid_") {
+		task, err := client.GetDownloadStationTask(arg)
+		if err != nil {
+			return nil, err
+		}
+		if task.Status != "finished" {
+			return nil, fmt.Errorf("Task %v has not been downloaded yet", task.Title)
+		}
+		archivePath = "/" + path.Join(task.AdditinalTaskInfo.TaskDetail.Destination, task.Title)
+	}
+
+	info, err := client.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir {
+		return []string{archivePath}, nil
+	}
+
+	var archives []string
+	it := client.NewFileIterator(archivePath, synoclient.ListOptions{
+		Pattern:  "*.rar,*.zip,*.7z",
+		FileType: "file",
+		SortBy:   "name",
+	})
+	for it.Next() {
+		file := it.File()
+		if volume := rarVolume.FindStringSubmatch(file.Name); volume != nil && volume[1] != "1" {
+			continue
+		}
+		archives = append(archives, file.Path)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(archives) == 0 {
+		return nil, fmt.Errorf("No archives found in %v", archivePath)
+	}
+	return archives, nil
+}
+
+// flattenArchiveItems lists the items of all folders in an archive
+func flattenArchiveItems(items []synoclient.ArchiveItem) []synoclient.ArchiveItem {
+	var flat []synoclient.ArchiveItem
+	for _, item := range items {
+		children := item.Items
+		item.Items = nil
+		flat = append(flat, item)
+		flat = append(flat, flattenArchiveItems(children)...)
+	}
+	return flat
+}
+
+func printArchiveItems(items []synoclient.ArchiveItem) {
+	for _, item := range items {
+		if item.IsDir {
+			fmt.Printf("%10v %v/\n", "", item.Path)
+		} else {
+			fmt.Printf("%10v %v\n", ByteCountSI(item.Size), item.Path)
+		}
+	}
+}
+
+func compressFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("compress")
+	format := flags.String("format", "zip", "Archive format, zip or 7z")
+	level := flags.String("level", "moderate", "Compression level: store, fastest, moderate or best")
+	password := flags.String("password", "", "Archive password")
+
+	positional := parseArgs(flags, args)
+	if len(positional) < 2 {
+		flags.Usage()
+		return
+	}
+	archive, paths := positional[0], positional[1:]
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	task, err := client.Compress(paths, archive, synoclient.CompressOptions{
+		Format:   *format,
+		Level:    *level,
+		Password: *password,
+	})
+	if err == nil {
+		err = waitFileTask(task)
+	}
+	if err != nil {
+		printError(err)
+	} else {
+		printMessage("Created %v.\n", archive)
+	}
+	outputFileResults(pathResults(paths, "compressed", archive, err))
+}
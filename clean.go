@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// duration is a time.Duration read from JSON strings like "24h"
+type duration struct {
+	time.Duration
+}
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = value
+	return nil
+}
+
+// cleanupPolicy decides which download tasks are removed or resumed.
+// Zero values disable the corresponding rule.
+type cleanupPolicy struct {
+	// remove tasks finished for longer than FinishedAfter
+	FinishedAfter duration `json:"finished_after"`
+	// remove seeding tasks above SeedingRatio or seeding for longer than SeedingTime
+	SeedingRatio float64  `json:"seeding_ratio"`
+	SeedingTime  duration `json:"seeding_time"`
+	// resume errored tasks at most ErrorRetries times
+	ResumeErrors bool `json:"resume_errors"`
+	ErrorRetries int  `json:"error_retries"`
+	// remove errored tasks which are not resumed anymore
+	RemoveErrors bool `json:"remove_errors"`
+	// StateFile keeps the number of resumes between runs
+	StateFile string `json:"state_file"`
+}
+
+type cleanupAction struct {
+	task   synoclient.DownloadStationTask
+	resume bool
+	reason string
+}
+
+func loadCleanupPolicy(file string) (*cleanupPolicy, error) {
+	policyFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer policyFile.Close()
+
+	policy := &cleanupPolicy{
+		StateFile: filepath.Join(os.Getenv("HOME"), ".synogo.state.json"),
+	}
+	if err := json.NewDecoder(policyFile).Decode(policy); err != nil {
+		return nil, fmt.Errorf("Could not parse JSON policy: %v", err)
+	}
+	return policy, nil
+}
+
+// evaluate returns the actions for tasks, retries holds the number of
+// resumes of errored tasks so far
+func (p *cleanupPolicy) evaluate(tasks []synoclient.DownloadStationTask, retries map[string]int, now time.Time) []cleanupAction {
+	var actions []cleanupAction
+	for _, task := range tasks {
+		detail := task.AdditinalTaskInfo.TaskDetail
+		transfer := task.AdditinalTaskInfo.TaskTransfer
+
+		switch task.Status {
+		case "finished":
+			if p.FinishedAfter.Duration > 0 && !detail.CompletedTime.IsZero() && now.Sub(detail.CompletedTime) > p.FinishedAfter.Duration {
+				actions = append(actions, cleanupAction{task: task, reason: fmt.Sprintf("finished %v ago", now.Sub(detail.CompletedTime).Round(time.Minute))})
+			}
+
+		case "seeding":
+			downloaded := transfer.SizeDownloaded
+			if downloaded == 0 {
+				downloaded = task.Size
+			}
+			if p.SeedingRatio > 0 && downloaded > 0 && float64(transfer.SizeUploaded)/float64(downloaded) >= p.SeedingRatio {
+				actions = append(actions, cleanupAction{task: task, reason: fmt.Sprintf("seeding ratio %.2f", float64(transfer.SizeUploaded)/float64(downloaded))})
+			} else if p.SeedingTime.Duration > 0 && detail.SeedElapsed > p.SeedingTime.Duration {
+				actions = append(actions, cleanupAction{task: task, reason: fmt.Sprintf("seeding for %v", detail.SeedElapsed)})
+			}
+
+		case "error":
+			if p.ResumeErrors && retries[task.ID] < p.ErrorRetries {
+				actions = append(actions, cleanupAction{task: task, resume: true, reason: fmt.Sprintf("retry %v of %v", retries[task.ID]+1, p.ErrorRetries)})
+			} else if p.RemoveErrors {
+				actions = append(actions, cleanupAction{task: task, reason: fmt.Sprintf("failed after %v retries", retries[task.ID])})
+			}
+		}
+	}
+	return actions
+}
+
+func loadRetries(file string) map[string]int {
+	retries := make(map[string]int)
+	data, err := ioutil.ReadFile(file)
+	if err == nil {
+		json.Unmarshal(data, &retries)
+	}
+	return retries
+}
+
+func saveRetries(file string, retries map[string]int) error {
+	data, err := json.Marshal(retries)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+func cleanDownloadTasks(client *synoclient.Client, args []string) {
+	flags := newFlagSet("clean")
+	policyFile := flags.String("policy", filepath.Join(os.Getenv("HOME"), ".synogo.policy.json"), "JSON policy file")
+	dryRun := flags.Bool("dry-run", false, "Only report what would be done")
+	interval := flags.Duration("interval", 0, "Keep running and clean up at this interval")
+
+	if len(parseArgs(flags, args)) != 0 {
+		flags.Usage()
+		return
+	}
+
+	policy, err := loadCleanupPolicy(*policyFile)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	runCleanup(client, policy, *dryRun)
+	if *interval <= 0 {
+		return
+	}
+
+	for range time.Tick(*interval) {
+		runCleanup(client, policy, *dryRun)
+	}
+}
+
+// runCleanup applies the policy once
+func runCleanup(client *synoclient.Client, policy *cleanupPolicy, dryRun bool) {
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	tasks, err := client.ListDownloadStationTasks()
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	retries := loadRetries(policy.StateFile)
+	actions := policy.evaluate(tasks, retries, time.Now())
+
+	var remove, resume []string
+	for _, action := range actions {
+		verb := "remove"
+		if action.resume {
+			verb = "resume"
+			resume = append(resume, action.task.ID)
+		} else {
+			remove = append(remove, action.task.ID)
+		}
+		printMessage("%v %v (%v): %v\n", verb, action.task.ID, action.task.Title, action.reason)
+	}
+
+	if dryRun {
+		if len(actions) == 0 {
+			printMessage("Nothing to clean.\n")
+		}
+		var planned []taskResult
+		for _, action := range actions {
+			verb := "remove"
+			if action.resume {
+				verb = "resume"
+			}
+			planned = append(planned, taskResult{ID: action.task.ID, Action: fmt.Sprintf("%v (%v)", verb, action.reason)})
+		}
+		outputTaskResults(planned)
+		return
+	}
+
+	var results []taskResult
+	defer func() { outputTaskResults(results) }()
+
+	if len(remove) > 0 {
+		resp, err := client.DeleteDownloadStationTasks(strings.Join(remove, ","))
+		if err != nil {
+			printError(err)
+			results = append(results, idResults(remove, "deleted", err)...)
+		} else {
+			results = append(results, printTaskResults(client, resp, "delete", "deleted")...)
+		}
+	}
+
+	if len(resume) > 0 {
+		resp, err := client.ResumeDownloadStationTasks(strings.Join(resume, ","))
+		if err != nil {
+			printError(err)
+			results = append(results, idResults(resume, "resumed", err)...)
+		} else {
+			results = append(results, printTaskResults(client, resp, "resume", "resumed")...)
+			for _, id := range resume {
+				retries[id]++
+			}
+		}
+	}
+
+	// forget tasks which do not exist anymore
+	existing := make(map[string]bool)
+	for _, task := range tasks {
+		existing[task.ID] = true
+	}
+	for _, id := range remove {
+		delete(existing, id)
+	}
+	for id := range retries {
+		if !existing[id] {
+			delete(retries, id)
+		}
+	}
+
+	if err := saveRetries(policy.StateFile, retries); err != nil {
+		printError(err)
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// command is a synogo subcommand, e.g. 'synogo edit <id> --dest /video'.
+// Groups like 'synogo task' have subcommands instead of run.
+type command struct {
+	usage       string
+	run         func(client *synoclient.Client, args []string)
+	subcommands map[string]command
+}
+
+var commands map[string]command
+
+// fsCommandNames are the file commands also available as 'synogo fs <name>'
+var fsCommandNames = []string{
+	"access", "compress", "cp", "du", "extract", "fav", "find", "get", "jobs", "ls", "md5sum",
+	"mkdir", "mounts", "mv", "put", "rm", "share", "stat", "sync", "thumb",
+}
+
+func init() {
+	commands = map[string]command{
+		"access":   {"[-overwrite] <folder> [filename]", checkAccess, nil},
+		"clean":    {"[--policy file] [--dry-run] [--interval duration]", cleanDownloadTasks, nil},
+		"compress": {"[--format zip|7z] [--level level] [--password password] <archive> <path>...", compressFiles, nil},
+		"cp":       {"[-overwrite|-skip] [-accurate] <path>... <dest>", copyFiles, nil},
+		"daemon":   {"--watch dir [--interval duration] [-s] [--policy file]", runDaemon, nil},
+		"du":       {"[-b] <path>...", diskUsage, nil},
+		"edit":     {"<id> [--dest dir] [--skip pattern] [--want pattern] [--priority level]", editDownloadTask, nil},
+		"exporter": {"[--listen addr]", runExporter, nil},
+		"extract":  {"<task id|path> [--to dir] [--password password] [--overwrite] [--keep-dir] [--subfolder] [--list]", extractArchive, nil},
+		"fav":      {"[--add <folder> <name>] [--rename <folder> <name>] [--delete <folder>...] [--clear]", manageFavorites, nil},
+		"find":     {"<folder>... [-name pattern] [-ext ext] [-type f|d] [-size [+-]size] [-mtime [+-]days] [-user owner] [-l] [-delete|-move dir]", findFiles, nil},
+		"get":      {"[-retries n] <remote> <local>", getFile, nil},
+		"jobs":     {"[-api name] [-clear] [-wait id]", listJobs, nil},
+		"ls":       {"[-l] [-pattern pattern] [-sort field] [-reverse] [path]", listFiles, nil},
+		"md5sum":   {"<path>... | -check <local file> <path>", md5Files, nil},
+		"mkdir":    {"[-p] <path>...", makeFolders, nil},
+		"mounts":   {"[-type cifs|nfs|iso]", listMounts, nil},
+		"mv":       {"[-overwrite|-skip] [-accurate] <path>... <dest>", moveFiles, nil},
+		"organize": {"[--rules file] [--dry-run]", organizeDownloadTasks, nil},
+		"put":      {"[-r] [-j jobs] [-overwrite|-skip] <local> <remote dir>", putFiles, nil},
+		"rm":       {"[-r] <path>...", removeFiles, nil},
+		"serve":    {"webdav [--listen addr] [--root folder] [--auth user:password] | api [--listen addr] [--token token]", serve, nil},
+		"share":    {"<path>... [--expires 7d] [--available date] [--password password] | --list | --delete <id>... | --clear", shareFiles, nil},
+		"stat":     {"<path>...", statFiles, nil},
+		"sync":     {"[-reverse] [-delete] [-dry-run] [-checksum] [-include pattern] [-exclude pattern] [-j jobs] <localdir> <remote>", syncFiles, nil},
+		"thumb":    {"[-size small|medium|large|original] [-rotate n] <remote> [local] | -info <remote>", getThumbnail, nil},
+	}
+
+	fsCommands := map[string]command{
+		"rename": {"<path> <name>", renameFile, nil},
+	}
+	for _, name := range fsCommandNames {
+		fsCommands[name] = commands[name]
+	}
+	commands["fs"] = command{"<command> [arguments]", nil, fsCommands}
+
+	commands["task"] = command{"<command> [arguments]", nil, map[string]command{
+		"add":      {"[-s] [-f file] [uri]...", addTasks, nil},
+		"clean":    commands["clean"],
+		"clear":    {"", clearTasks, nil},
+		"edit":     commands["edit"],
+		"info":     {"<id>", showTask, nil},
+		"list":     {"", listTasks, nil},
+		"mv":       {"<id> <destination>", moveTask, nil},
+		"organize": commands["organize"],
+		"pause":    {"<id>...", pauseTasks, nil},
+		"resume":   {"<id>...", resumeTasks, nil},
+		"rm":       {"<id>...", removeTasks, nil},
+	}}
+	// task commands whose names are not taken by file commands are also
+	// available on the top level
+	for _, name := range []string{"info", "pause", "resume"} {
+		commands[name] = commands["task"].subcommands[name]
+	}
+
+	commands["help"] = command{"[command]...", showHelp, nil}
+	commands["completion"] = command{"bash|zsh|fish", printCompletion, nil}
+	// __complete is run by the completion scripts
+	commands["__complete"] = command{"<word>...", completeArgs, nil}
+}
+
+var (
+	statusMu   sync.Mutex
+	exitStatus int
+)
+
+// setExitStatus raises the exit status, 1 for failures and 2 for usage
+// errors
+func setExitStatus(status int) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	if status > exitStatus {
+		exitStatus = status
+	}
+}
+
+// lookupCommand returns the command named by words, e.g. task pause
+func lookupCommand(words []string) (command, bool) {
+	cmd := command{subcommands: commands}
+	for _, word := range words {
+		sub, ok := cmd.subcommands[word]
+		if !ok {
+			return command{}, false
+		}
+		cmd = sub
+	}
+	return cmd, true
+}
+
+// runCommand runs cmd, groups run the subcommand named by the first
+// argument
+func runCommand(client *synoclient.Client, name string, cmd command, args []string) {
+	if cmd.subcommands == nil {
+		cmd.run(client, args)
+		return
+	}
+
+	if len(args) == 0 || isHelpFlag(args[0]) {
+		printCommands(name, cmd.subcommands)
+		if len(args) == 0 {
+			setExitStatus(2)
+		}
+		return
+	}
+	sub, ok := cmd.subcommands[args[0]]
+	if !ok {
+		printErrorf("Unknown command %s %s\n", name, args[0])
+		printCommands(name, cmd.subcommands)
+		setExitStatus(2)
+		return
+	}
+	runCommand(client, name+" "+args[0], sub, args[1:])
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// showHelp prints the help of a command, e.g. 'synogo help task pause'
+func showHelp(client *synoclient.Client, args []string) {
+	if len(args) == 0 || isHelpFlag(args[0]) {
+		printUsage()
+		return
+	}
+	cmd, ok := lookupCommand(args)
+	if !ok {
+		printErrorf("Unknown command %s\n", strings.Join(args, " "))
+		setExitStatus(2)
+		return
+	}
+	// commands print their help including flags on -h
+	runCommand(client, strings.Join(args, " "), cmd, []string{"-h"})
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// newFlagSet returns a flag set for the named subcommand
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s %s:\n", os.Args[0], name)
+		cmd, _ := lookupCommand(strings.Fields(name))
+		fmt.Fprintf(os.Stderr, "  %s %s %s\n", os.Args[0], name, cmd.usage)
+		flags.PrintDefaults()
+		setExitStatus(2)
+	}
+	return flags
+}
+
+// parseArgs parses flags placed anywhere between positional arguments
+// and returns the positional arguments
+func parseArgs(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	return positional
+}
+
+// printCommands lists the subcommands of group, the top level commands if
+// group is empty
+func printCommands(group string, cmds map[string]command) {
+	var names []string
+	for name := range cmds {
+		if !strings.HasPrefix(name, "__") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	if group != "" {
+		fmt.Fprintf(os.Stderr, "Usage of %s %s:\n", os.Args[0], group)
+		fmt.Fprintf(os.Stderr, "  %s %s <command> [arguments]\n\n", os.Args[0], group)
+	}
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", name, cmds[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s help %s<command>' for the flags of a command.\n", os.Args[0], strings.TrimPrefix(group+" ", " "))
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// completionCacheTTL is how long task ids and folder listings are reused,
+// so completing does not log in on every key press
+const completionCacheTTL = 30 * time.Second
+
+// taskIDArgs are the commands taking task ids by argument position, -1
+// for every argument
+var taskIDArgs = map[string]int{
+	"edit":        0,
+	"info":        0,
+	"pause":       -1,
+	"resume":      -1,
+	"task edit":   0,
+	"task info":   0,
+	"task mv":     0,
+	"task pause":  -1,
+	"task resume": -1,
+	"task rm":     -1,
+}
+
+// localArgs are the arguments of file commands which are local files
+var localArgs = map[string]int{
+	"get":  1,
+	"put":  0,
+	"sync": 0,
+}
+
+// remoteFlags take a remote folder as value
+var remoteFlags = map[string]bool{
+	"dest": true,
+	"move": true,
+	"root": true,
+	"to":   true,
+}
+
+var usageFlag = regexp.MustCompile(`(^|[\s\[|])(--?[a-z][a-z-]*)`)
+
+func printCompletion(client *synoclient.Client, args []string) {
+	flags := newFlagSet("completion")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+
+	name := filepath.Base(os.Args[0])
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	switch positional[0] {
+	case "bash":
+		fmt.Printf(bashCompletion, fn, name, fn, name)
+	case "zsh":
+		fmt.Printf(zshCompletion, name, fn, name, fn, name)
+	case "fish":
+		fmt.Printf(fishCompletion, fn, name, name, fn)
+	default:
+		flags.Usage()
+	}
+}
+
+const bashCompletion = `# bash completion, load with: source <(synogo completion bash)
+%s() {
+	local IFS=$'\n'
+	local candidates
+	candidates=($(%s __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null | cut -f1))
+	COMPREPLY=("${candidates[@]}")
+	if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == */ ]]; then
+		compopt -o nospace
+	fi
+}
+complete -o default -F %s %s
+`
+
+const zshCompletion = `#compdef %s
+# zsh completion, load with: source <(synogo completion zsh)
+%s() {
+	local -a lines values folders
+	local line value
+	lines=(${(f)"$(%s __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	for line in $lines; do
+		value=${line%%%%$'\t'*}
+		value=${value//:/\\:}
+		if [[ $line == *$'\t'* ]]; then
+			value="$value:${line#*$'\t'}"
+		fi
+		if [[ $line == */ ]]; then
+			folders+=("$value")
+		else
+			values+=("$value")
+		fi
+	done
+	if (( ${#values} + ${#folders} == 0 )); then
+		_files
+		return
+	fi
+	(( ${#values} )) && _describe 'values' values
+	(( ${#folders} )) && _describe 'folders' folders -S ''
+}
+compdef %s %s
+`
+
+const fishCompletion = `# fish completion, load with: synogo completion fish | source
+function %s
+	set -l previous (commandline -opc)
+	set -l current (commandline -ct)
+	set -l candidates (%s __complete $previous[2..-1] "$current" 2>/dev/null)
+	if test (count $candidates) -eq 0
+		__fish_complete_path "$current"
+		return
+	end
+	printf '%%s\n' $candidates
+end
+complete -c %s -f -a '(%s)'
+`
+
+// completeArgs prints candidates for the last of args, the word being
+// completed, one per line as value and tab separated description
+func completeArgs(client *synoclient.Client, args []string) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current, previous := args[len(args)-1], args[:len(args)-1]
+	for _, candidate := range completions(client, previous, current) {
+		fmt.Println(candidate)
+	}
+}
+
+func completions(client *synoclient.Client, previous []string, current string) []string {
+	// find the command, the remaining words are its arguments
+	cmd := command{subcommands: commands}
+	var name []string
+	for len(previous) > 0 && cmd.subcommands != nil {
+		sub, ok := cmd.subcommands[previous[0]]
+		if !ok {
+			break
+		}
+		cmd, name, previous = sub, append(name, previous[0]), previous[1:]
+	}
+
+	if len(name) == 0 {
+		return completeLegacyFlags(client, previous, current)
+	}
+
+	if cmd.subcommands != nil {
+		var candidates []string
+		for sub, subcmd := range cmd.subcommands {
+			if strings.HasPrefix(sub, current) {
+				candidates = append(candidates, sub+"\t"+subcmd.usage)
+			}
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+
+	if strings.HasPrefix(current, "-") {
+		flags := usageFlags(cmd.usage)
+		// Go flags accept one or two dashes
+		if strings.HasPrefix(current, "--") {
+			for i, f := range flags {
+				flags[i] = "--" + strings.TrimLeft(f, "-")
+			}
+		}
+		return filterPrefix(flags, current)
+	}
+
+	if len(previous) > 0 && remoteFlags[strings.TrimLeft(previous[len(previous)-1], "-")] {
+		return completeRemotePath(client, current)
+	}
+
+	var position int
+	for _, arg := range previous {
+		if !strings.HasPrefix(arg, "-") {
+			position++
+		}
+	}
+
+	key := strings.TrimPrefix(strings.Join(name, " "), "fs ")
+	if index, ok := taskIDArgs[key]; ok && (index == -1 || index == position) {
+		return completeTaskIDs(client, current)
+	}
+	if index, ok := localArgs[key]; ok && index == position {
+		return nil
+	}
+	if key == "task mv" || key == "rename" || isFSCommand(key) {
+		return completeRemotePath(client, current)
+	}
+	return nil
+}
+
+// completeLegacyFlags completes the top level, commands and the single
+// letter flags
+func completeLegacyFlags(client *synoclient.Client, previous []string, current string) []string {
+	if len(previous) > 0 {
+		switch strings.TrimLeft(previous[len(previous)-1], "-") {
+		case "d", "p", "r":
+			// comma separated ids
+			prefix := current[:strings.LastIndex(current, ",")+1]
+			var candidates []string
+			for _, candidate := range completeTaskIDs(client, current[len(prefix):]) {
+				candidates = append(candidates, prefix+candidate)
+			}
+			return candidates
+		case "i", "m":
+			return completeTaskIDs(client, current)
+		case "output":
+			return filterPrefix(outputFormats, current)
+		case "f", "template":
+			return nil
+		}
+	}
+
+	var candidates []string
+	if strings.HasPrefix(current, "-") {
+		flag.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "-"+f.Name+"\t"+f.Usage)
+		})
+	} else {
+		for name, cmd := range commands {
+			if !strings.HasPrefix(name, "__") {
+				candidates = append(candidates, name+"\t"+cmd.usage)
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return filterPrefix(candidates, current)
+}
+
+func isFSCommand(name string) bool {
+	for _, fsName := range fsCommandNames {
+		if name == fsName {
+			return true
+		}
+	}
+	return false
+}
+
+// usageFlags returns the flags mentioned in a command usage
+func usageFlags(usage string) []string {
+	seen := map[string]bool{}
+	var flags []string
+	for _, match := range usageFlag.FindAllStringSubmatch(usage, -1) {
+		if !seen[match[2]] {
+			seen[match[2]] = true
+			flags = append(flags, match[2])
+		}
+	}
+	return flags
+}
+
+// filterPrefix returns the candidates whose value starts with prefix
+func filterPrefix(candidates []string, prefix string) []string {
+	var filtered []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
+
+func completeTaskIDs(client *synoclient.Client, current string) []string {
+	candidates := cachedCandidates(client, "tasks", func() ([]string, error) {
+		tasks, err := client.ListDownloadStationTasks()
+		if err != nil {
+			return nil, err
+		}
+		var candidates []string
+		for _, task := range tasks {
+			candidates = append(candidates, fmt.Sprintf("%v\t%v (%v)", task.ID, task.Title, task.Status))
+		}
+		return candidates, nil
+	})
+	return filterPrefix(candidates, current)
+}
+
+// completeRemotePath completes absolute FileStation paths, folders end with
+// a slash
+func completeRemotePath(client *synoclient.Client, current string) []string {
+	if current == "" {
+		current = "/"
+	}
+	if !strings.HasPrefix(current, "/") {
+		return nil
+	}
+
+	// an empty folder lists the shared folders
+	folder := current[:strings.LastIndex(current, "/")]
+	candidates := cachedCandidates(client, "ls "+folder, func() ([]string, error) {
+		var candidates []string
+		it := client.NewFileIterator(folder, synoclient.ListOptions{SortBy: "name"})
+		for it.Next() {
+			file := it.File()
+			name := path.Join("/", folder, file.Name)
+			if file.IsDir {
+				name += "/"
+			}
+			candidates = append(candidates, name)
+		}
+		return candidates, it.Err()
+	})
+	return filterPrefix(candidates, current)
+}
+
+// completionCache is stored in ~/.synogo.completion.json
+type completionCache map[string]cachedCompletion
+
+type cachedCompletion struct {
+	Time       time.Time `json:"time"`
+	Candidates []string  `json:"candidates"`
+}
+
+// cachedCandidates returns the candidates of key from the cache or loads
+// them after logging in
+func cachedCandidates(client *synoclient.Client, key string, load func() ([]string, error)) []string {
+	file := filepath.Join(os.Getenv("HOME"), ".synogo.completion.json")
+	key = client.Host + " " + key
+
+	cache := make(completionCache)
+	if data, err := ioutil.ReadFile(file); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	if cached, ok := cache[key]; ok && time.Since(cached.Time) < completionCacheTTL {
+		return cached.Candidates
+	}
+
+	if _, err := client.Login(); err != nil {
+		return nil
+	}
+	defer client.Logout()
+	candidates, err := load()
+	if err != nil {
+		return nil
+	}
+
+	for cachedKey, cached := range cache {
+		if time.Since(cached.Time) >= completionCacheTTL {
+			delete(cache, cachedKey)
+		}
+	}
+	cache[key] = cachedCompletion{Time: time.Now(), Candidates: candidates}
+	if data, err := json.Marshal(cache); err == nil {
+		ioutil.WriteFile(file, data, 0600)
+	}
+	return candidates
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func TestCompletions(t *testing.T) {
+	home, err := ioutil.TempDir("", "synogo-completion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	// the cache is fresh, so completing does not log in
+	client := &synoclient.Client{Host: "nas.invalid:5000", Scheme: "http"}
+	cache := completionCache{
+		client.Host + " tasks": {Time: time.Now(), Candidates: []string{
+			"dbid_1\tubuntu.iso (downloading)",
+			"dbid_2\tdebian.iso (paused)",
+		}},
+		client.Host + " ls /video": {Time: time.Now(), Candidates: []string{
+			"/video/a.txt",
+			"/video/movies/",
+		}},
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(home, ".synogo.completion.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{"dbid_1\tubuntu.iso (downloading)", "dbid_2\tdebian.iso (paused)"}
+	tests := []struct {
+		name     string
+		previous []string
+		current  string
+		want     []string
+	}{
+		{name: "top level alias", previous: []string{"pause"}, current: "dbid_", want: ids},
+		{name: "alias every argument", previous: []string{"resume", "dbid_1"}, current: "", want: ids},
+		{name: "info first argument", previous: []string{"info"}, current: "dbid_2", want: ids[1:]},
+		{name: "info second argument", previous: []string{"info", "dbid_1"}, current: "", want: nil},
+		{name: "task group", previous: []string{"task", "rm"}, current: "dbid_1", want: ids[:1]},
+		{name: "legacy id list", previous: []string{"-d"}, current: "dbid_1,dbid_2", want: []string{"dbid_1,dbid_2\tdebian.iso (paused)"}},
+		{name: "subcommand", previous: []string{"task"}, current: "pa", want: []string{"pause\t<id>..."}},
+		{name: "command", previous: nil, current: "pau", want: []string{"pause\t<id>..."}},
+		{name: "remote path", previous: []string{"ls"}, current: "/video/m", want: []string{"/video/movies/"}},
+		{name: "fs group remote path", previous: []string{"fs", "stat"}, current: "/video/", want: []string{"/video/a.txt", "/video/movies/"}},
+		{name: "remote flag", previous: []string{"edit", "dbid_1", "--dest"}, current: "/video/a", want: []string{"/video/a.txt"}},
+		{name: "local argument", previous: []string{"get", "/video/a.txt"}, current: "", want: nil},
+		{name: "flags", previous: []string{"mkdir"}, current: "-", want: []string{"-p"}},
+		{name: "output formats", previous: []string{"--output"}, current: "j", want: []string{"json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completions(client, tt.previous, tt.current); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("completions(%q, %q) = %q, want %q", tt.previous, tt.current, got, tt.want)
+			}
+		})
+	}
+}
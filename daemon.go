@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// files younger than settleTime may still be written
+const settleTime = 5 * time.Second
+
+func runDaemon(client *synoclient.Client, args []string) {
+	flags := newFlagSet("daemon")
+	watch := flags.String("watch", "", "Directory to watch for .torrent, .nzb, .magnet and .txt files")
+	interval := flags.Duration("interval", 30*time.Second, "How often to scan the watched directory")
+	skip := flags.Bool("s", false, "Skip URIs of existing download tasks")
+	policyFile := flags.String("policy", "", "Also clean up download tasks using JSON policy file")
+
+	if len(parseArgs(flags, args)) != 0 || *watch == "" {
+		flags.Usage()
+		return
+	}
+
+	for _, dir := range []string{"done", "failed"} {
+		if err := os.MkdirAll(filepath.Join(*watch, dir), 0755); err != nil {
+			printError(err)
+			return
+		}
+	}
+
+	var policy *cleanupPolicy
+	if *policyFile != "" {
+		var err error
+		if policy, err = loadCleanupPolicy(*policyFile); err != nil {
+			printError(err)
+			return
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	log.Printf("Watching %v", *watch)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		scanWatchFolder(client, *watch, *skip)
+		if policy != nil {
+			runCleanup(client, policy, false)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-signals:
+			log.Println("Stopped.")
+			return
+		}
+	}
+}
+
+// scanWatchFolder submits all settled files of the watched directory
+func scanWatchFolder(client *synoclient.Client, dir string, skipDuplicates bool) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.Mode().IsRegular() || time.Since(entry.ModTime()) < settleTime {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".torrent", ".nzb", ".magnet", ".txt":
+			files = append(files, entry.Name())
+		}
+	}
+
+	if len(files) == 0 {
+		return
+	}
+
+	// Login
+	_, err = client.Login()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer client.Logout()
+
+	index, err := newTaskIndex(client, skipDuplicates)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for _, name := range files {
+		errs := submitWatchFile(client, filepath.Join(dir, name), index)
+		if len(errs) == 0 {
+			log.Printf("%v submitted", name)
+			moveWatchFile(dir, name, "done", nil)
+		} else {
+			log.Printf("%v failed: %v", name, errs[0])
+			moveWatchFile(dir, name, "failed", errs)
+		}
+	}
+}
+
+// submitWatchFile creates download tasks from file and returns the failures
+func submitWatchFile(client *synoclient.Client, file string, index *synoclient.TaskIndex) []string {
+	f, err := os.Open(file)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".torrent", ".nzb":
+		info, err := f.Stat()
+		if err != nil {
+			return []string{err.Error()}
+		}
+		if err := client.CreateDownloadStationTaskFromFile(filepath.Base(file), f, info.Size()); err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+
+	// .magnet and .txt files hold one URI per line
+	var errs []string
+	err = queueDownloadTasks(client, f, index, func(line int, data *synoclient.TaskAddError) {
+		if data.Err == synoclient.ErrDuplicateTask {
+			log.Printf("Task %v skipped: %v", data.Name, data.Err)
+			return
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", data.Name, data.Err))
+	})
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	return errs
+}
+
+// moveWatchFile moves a processed file into subdir and writes an error sidecar
+func moveWatchFile(dir string, name string, subdir string, errs []string) {
+	target := filepath.Join(dir, subdir, name)
+	if _, err := os.Stat(target); err == nil {
+		ext := filepath.Ext(name)
+		target = filepath.Join(dir, subdir, fmt.Sprintf("%v-%v%v", strings.TrimSuffix(name, ext), time.Now().Unix(), ext))
+	}
+
+	if err := os.Rename(filepath.Join(dir, name), target); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if len(errs) > 0 {
+		sidecar := []byte(strings.Join(errs, "\n") + "\n")
+		if err := ioutil.WriteFile(target+".error", sidecar, 0644); err != nil {
+			log.Println(err)
+		}
+	}
+}
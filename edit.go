@@ -0,0 +1,117 @@
+package main
+
+import (
+	"path"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func editDownloadTask(client *synoclient.Client, args []string) {
+	flags := newFlagSet("edit")
+	dest := flags.String("dest", "", "Change task destination folder")
+	var skip, want stringList
+	flags.Var(&skip, "skip", "Disable BT files matching pattern (repeatable)")
+	flags.Var(&want, "want", "Enable BT files matching pattern (repeatable)")
+	priority := flags.String("priority", "", "Set priority (low, normal, high) of wanted files")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 || (*dest == "" && len(skip) == 0 && len(want) == 0 && *priority == "") {
+		flags.Usage()
+		return
+	}
+	taskID := positional[0]
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var results []taskResult
+	defer func() { outputTaskResults(results) }()
+
+	if *dest != "" {
+		resp, err := client.EditDownloadStationTasks(taskID, *dest)
+		if err != nil {
+			printError(err)
+			return
+		}
+		results = append(results, printTaskResults(client, resp, "move", "moved to "+*dest)...)
+	}
+
+	if len(skip) == 0 && len(want) == 0 && *priority == "" {
+		return
+	}
+
+	files, err := client.ListDownloadStationTaskFiles(taskID)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	var skipped, wanted []int
+	var skippedNames, wantedNames []string
+	for _, file := range files {
+		switch {
+		case matchTaskFile(skip, file.Name):
+			skipped = append(skipped, file.Index)
+			skippedNames = append(skippedNames, file.Name)
+		// priority without -want applies to all files that are not skipped
+		case matchTaskFile(want, file.Name) || (len(want) == 0 && *priority != "" && file.Wanted):
+			wanted = append(wanted, file.Index)
+			wantedNames = append(wantedNames, file.Name)
+		}
+	}
+
+	if len(skipped) > 0 {
+		err := client.SetDownloadStationTaskFiles(taskID, skipped, false, "")
+		results = append(results, taskFileResults(taskID, skippedNames, "skipped", err)...)
+		if err != nil {
+			printError(err)
+			return
+		}
+		printMessage("%v file(s) skipped.\n", len(skipped))
+	}
+
+	if len(wanted) > 0 {
+		err := client.SetDownloadStationTaskFiles(taskID, wanted, true, *priority)
+		results = append(results, taskFileResults(taskID, wantedNames, "wanted", err)...)
+		if err != nil {
+			printError(err)
+			return
+		}
+		printMessage("%v file(s) wanted.\n", len(wanted))
+	}
+
+	if len(skipped) == 0 && len(wanted) == 0 {
+		printMessage("No matching files found.\n")
+	}
+}
+
+// taskFileResults are the results of a single operation on several files of a task
+func taskFileResults(taskID string, names []string, action string, err error) []taskResult {
+	var results []taskResult
+	for _, name := range names {
+		result := taskResult{ID: taskID, Action: action + " " + name}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// matchTaskFile reports whether the file name or its base name matches any pattern
+func matchTaskFile(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// taskStatuses are reported even if no task has the status, so alerts
+// do not depend on absent series
+var taskStatuses = []string{
+	"waiting", "downloading", "paused", "finishing", "finished", "hash_checking",
+	"seeding", "filehosting_waiting", "extracting", "error",
+}
+
+// exporter publishes Download Station metrics in the Prometheus text format
+type exporter struct {
+	client *synoclient.Client
+
+	mu           sync.Mutex
+	apiErrors    map[int]int64
+	logins       int64
+	loginErrors  int64
+	loginSeconds float64
+}
+
+func runExporter(client *synoclient.Client, args []string) {
+	flags := newFlagSet("exporter")
+	listen := flags.String("listen", ":9108", "Address to listen on")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	e := &exporter{client: client, apiErrors: map[int]int64{}}
+	client.OnLogin = e.observeLogin
+	client.KeepSession = true
+
+	// Login, the session is renewed when it expires
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+
+	log.Printf("Serving metrics on %v/metrics", *listen)
+	listenAndServe(*listen, mux)
+}
+
+func (e *exporter) observeLogin(duration time.Duration, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logins++
+	e.loginSeconds = duration.Seconds()
+	if err != nil {
+		e.loginErrors++
+	}
+}
+
+// observeError counts failed API calls by Synology error code, 0 for
+// transport failures
+func (e *exporter) observeError(err error) {
+	if err == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.apiErrors[synoclient.ErrorCode(err)]++
+}
+
+// ServeHTTP collects the metrics on every scrape
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var m metricWriter
+	start := time.Now()
+	up := 1
+
+	tasks, err := e.client.ListDownloadStationTasks()
+	e.observeError(err)
+	if err != nil {
+		log.Println(err)
+		up = 0
+	} else {
+		e.writeTaskMetrics(&m, tasks)
+	}
+
+	stats, err := e.client.GetDownloadStationStatistic()
+	e.observeError(err)
+	if err != nil {
+		log.Println(err)
+		up = 0
+	} else {
+		writeStatisticMetrics(&m, stats)
+	}
+
+	e.writeClientMetrics(&m)
+
+	m.header("synogo_up", "gauge", "Whether the NAS could be queried")
+	m.sample("synogo_up", nil, float64(up))
+	m.header("synogo_scrape_duration_seconds", "gauge", "Duration of collecting the metrics")
+	m.sample("synogo_scrape_duration_seconds", nil, time.Since(start).Seconds())
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(m.Bytes())
+}
+
+func (e *exporter) writeTaskMetrics(m *metricWriter, tasks []synoclient.DownloadStationTask) {
+	byStatus := map[string]int{}
+	for _, status := range taskStatuses {
+		byStatus[status] = 0
+	}
+	var downloaded, uploaded int64
+	for _, task := range tasks {
+		byStatus[task.Status]++
+		downloaded += task.AdditinalTaskInfo.TaskTransfer.SizeDownloaded
+		uploaded += task.AdditinalTaskInfo.TaskTransfer.SizeUploaded
+	}
+
+	var statuses []string
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	m.header("synogo_tasks", "gauge", "Number of download tasks by status")
+	for _, status := range statuses {
+		m.sample("synogo_tasks", []string{"status", status}, float64(byStatus[status]))
+	}
+	m.header("synogo_downloaded_bytes", "gauge", "Bytes downloaded by all tasks")
+	m.sample("synogo_downloaded_bytes", nil, float64(downloaded))
+	m.header("synogo_uploaded_bytes", "gauge", "Bytes uploaded by all tasks")
+	m.sample("synogo_uploaded_bytes", nil, float64(uploaded))
+
+	perTask := []struct {
+		name  string
+		help  string
+		value func(synoclient.DownloadStationTask) int64
+	}{
+		{"synogo_task_size_bytes", "Size of the task", func(t synoclient.DownloadStationTask) int64 { return t.Size }},
+		{"synogo_task_downloaded_bytes", "Bytes downloaded by the task", func(t synoclient.DownloadStationTask) int64 {
+			return t.AdditinalTaskInfo.TaskTransfer.SizeDownloaded
+		}},
+		{"synogo_task_uploaded_bytes", "Bytes uploaded by the task", func(t synoclient.DownloadStationTask) int64 {
+			return t.AdditinalTaskInfo.TaskTransfer.SizeUploaded
+		}},
+		{"synogo_task_download_speed_bytes", "Download speed of the task in bytes per second", func(t synoclient.DownloadStationTask) int64 {
+			return t.AdditinalTaskInfo.TaskTransfer.SpeedDownload
+		}},
+		{"synogo_task_upload_speed_bytes", "Upload speed of the task in bytes per second", func(t synoclient.DownloadStationTask) int64 {
+			return t.AdditinalTaskInfo.TaskTransfer.SpeedUpload
+		}},
+	}
+	// the status is left out of the labels, so a status change does not
+	// start a new series
+	for _, metric := range perTask {
+		m.header(metric.name, "gauge", metric.help)
+		for _, task := range tasks {
+			m.sample(metric.name, []string{"id", task.ID, "title", task.Title}, float64(metric.value(task)))
+		}
+	}
+}
+
+func writeStatisticMetrics(m *metricWriter, stats synoclient.DownloadStationStatistic) {
+	m.header("synogo_download_speed_bytes", "gauge", "Total download speed in bytes per second")
+	m.sample("synogo_download_speed_bytes", nil, float64(stats.SpeedDownload+stats.EmuleSpeedDownload))
+	m.header("synogo_upload_speed_bytes", "gauge", "Total upload speed in bytes per second")
+	m.sample("synogo_upload_speed_bytes", nil, float64(stats.SpeedUpload+stats.EmuleSpeedUpload))
+}
+
+func (e *exporter) writeClientMetrics(m *metricWriter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var codes []int
+	for code := range e.apiErrors {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	m.header("synogo_api_errors_total", "counter", "Failed API calls by Synology error code, 0 for connection failures")
+	for _, code := range codes {
+		m.sample("synogo_api_errors_total", []string{"code", fmt.Sprint(code)}, float64(e.apiErrors[code]))
+	}
+	m.header("synogo_logins_total", "counter", "Number of logins")
+	m.sample("synogo_logins_total", nil, float64(e.logins))
+	m.header("synogo_login_errors_total", "counter", "Number of failed logins")
+	m.sample("synogo_login_errors_total", nil, float64(e.loginErrors))
+	m.header("synogo_login_duration_seconds", "gauge", "Duration of the last login")
+	m.sample("synogo_login_duration_seconds", nil, e.loginSeconds)
+}
+
+// metricWriter writes the Prometheus text exposition format
+type metricWriter struct {
+	bytes.Buffer
+}
+
+func (m *metricWriter) header(name string, metricType string, help string) {
+	fmt.Fprintf(m, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// sample writes a value with labels given as name, value pairs
+func (m *metricWriter) sample(name string, labels []string, value float64) {
+	m.WriteString(name)
+	if len(labels) > 0 {
+		var pairs []string
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1])))
+		}
+		fmt.Fprintf(m, "{%s}", strings.Join(pairs, ","))
+	}
+	fmt.Fprintf(m, " %v\n", value)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func TestExporterMetrics(t *testing.T) {
+	tasks := []synoclient.DownloadStationTask{
+		{
+			ID:     "dbid_1",
+			Size:   1000,
+			Status: "downloading",
+			Title:  `Some "quoted" title`,
+			AdditinalTaskInfo: synoclient.AdditinalTaskInfo{TaskTransfer: synoclient.TaskTransfer{
+				SizeDownloaded: 250, SpeedDownload: 50,
+			}},
+		},
+		{
+			ID:     "dbid_2",
+			Size:   2000,
+			Status: "seeding",
+			Title:  "done.iso",
+			AdditinalTaskInfo: synoclient.AdditinalTaskInfo{TaskTransfer: synoclient.TaskTransfer{
+				SizeDownloaded: 2000, SizeUploaded: 300, SpeedUpload: 10,
+			}},
+		},
+	}
+	stats := synoclient.DownloadStationStatistic{SpeedDownload: 50, SpeedUpload: 10, EmuleSpeedDownload: 5}
+
+	var m metricWriter
+	(&exporter{}).writeTaskMetrics(&m, tasks)
+	writeStatisticMetrics(&m, stats)
+
+	want := `# HELP synogo_tasks Number of download tasks by status
+# TYPE synogo_tasks gauge
+synogo_tasks{status="downloading"} 1
+synogo_tasks{status="error"} 0
+synogo_tasks{status="extracting"} 0
+synogo_tasks{status="filehosting_waiting"} 0
+synogo_tasks{status="finished"} 0
+synogo_tasks{status="finishing"} 0
+synogo_tasks{status="hash_checking"} 0
+synogo_tasks{status="paused"} 0
+synogo_tasks{status="seeding"} 1
+synogo_tasks{status="waiting"} 0
+# HELP synogo_downloaded_bytes Bytes downloaded by all tasks
+# TYPE synogo_downloaded_bytes gauge
+synogo_downloaded_bytes 2250
+# HELP synogo_uploaded_bytes Bytes uploaded by all tasks
+# TYPE synogo_uploaded_bytes gauge
+synogo_uploaded_bytes 300
+# HELP synogo_task_size_bytes Size of the task
+# TYPE synogo_task_size_bytes gauge
+synogo_task_size_bytes{id="dbid_1",title="Some \"quoted\" title"} 1000
+synogo_task_size_bytes{id="dbid_2",title="done.iso"} 2000
+# HELP synogo_task_downloaded_bytes Bytes downloaded by the task
+# TYPE synogo_task_downloaded_bytes gauge
+synogo_task_downloaded_bytes{id="dbid_1",title="Some \"quoted\" title"} 250
+synogo_task_downloaded_bytes{id="dbid_2",title="done.iso"} 2000
+# HELP synogo_task_uploaded_bytes Bytes uploaded by the task
+# TYPE synogo_task_uploaded_bytes gauge
+synogo_task_uploaded_bytes{id="dbid_1",title="Some \"quoted\" title"} 0
+synogo_task_uploaded_bytes{id="dbid_2",title="done.iso"} 300
+# HELP synogo_task_download_speed_bytes Download speed of the task in bytes per second
+# TYPE synogo_task_download_speed_bytes gauge
+synogo_task_download_speed_bytes{id="dbid_1",title="Some \"quoted\" title"} 50
+synogo_task_download_speed_bytes{id="dbid_2",title="done.iso"} 0
+# HELP synogo_task_upload_speed_bytes Upload speed of the task in bytes per second
+# TYPE synogo_task_upload_speed_bytes gauge
+synogo_task_upload_speed_bytes{id="dbid_1",title="Some \"quoted\" title"} 0
+synogo_task_upload_speed_bytes{id="dbid_2",title="done.iso"} 10
+# HELP synogo_download_speed_bytes Total download speed in bytes per second
+# TYPE synogo_download_speed_bytes gauge
+synogo_download_speed_bytes 55
+# HELP synogo_upload_speed_bytes Total upload speed in bytes per second
+# TYPE synogo_upload_speed_bytes gauge
+synogo_upload_speed_bytes 10
+`
+	if got := m.String(); got != want {
+		t.Errorf("metrics =\n%v\nwant\n%v", got, want)
+	}
+}
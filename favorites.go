@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func manageFavorites(client *synoclient.Client, args []string) {
+	flags := newFlagSet("fav")
+	add := flags.Bool("add", false, "Add <folder> as favorite <name>")
+	rename := flags.Bool("rename", false, "Rename the favorite of <folder> to <name>")
+	remove := flags.Bool("delete", false, "Delete favorites by folder")
+	clear := flags.Bool("clear", false, "Delete favorites of missing folders")
+
+	positional := parseArgs(flags, args)
+	if ((*add || *rename) && len(positional) != 2) || (*remove && len(positional) == 0) {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	switch {
+	case *add:
+		err := client.AddFavorite(positional[0], positional[1])
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("Favorite added.\n")
+		}
+		outputFavoriteResults([]favoriteResult{newFavoriteResult(positional[1], positional[0], "added", err)})
+
+	case *rename:
+		err := client.RenameFavorite(positional[0], positional[1])
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("Favorite renamed.\n")
+		}
+		outputFavoriteResults([]favoriteResult{newFavoriteResult(positional[1], positional[0], "renamed", err)})
+
+	case *remove:
+		var failed int
+		var results []favoriteResult
+		for _, folder := range positional {
+			err := client.DeleteFavorite(folder)
+			if err != nil {
+				printErrorf("Could not delete favorite %v: %v\n", folder, err)
+				failed++
+			}
+			results = append(results, newFavoriteResult("", folder, "deleted", err))
+		}
+		if failed == 0 {
+			printMessage("Favorites deleted.\n")
+		} else {
+			printMessage("%v favorite(s) deleted, %v failed.\n", len(positional)-failed, failed)
+		}
+		outputFavoriteResults(results)
+
+	case *clear:
+		// the favorites are listed before and after to tell which were deleted
+		before, _, err := client.ListFavorites(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		if err := client.ClearBrokenFavorites(); err != nil {
+			printError(err)
+			return
+		}
+		after, _, err := client.ListFavorites(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		remaining := make(map[string]bool)
+		for _, favorite := range after {
+			remaining[favorite.Path] = true
+		}
+		var results []favoriteResult
+		for _, favorite := range before {
+			if !remaining[favorite.Path] {
+				results = append(results, newFavoriteResult(favorite.Name, favorite.Path, "deleted", nil))
+			}
+		}
+		printMessage("Broken favorites deleted.\n")
+		outputFavoriteResults(results)
+
+	default:
+		favorites, _, err := client.ListFavorites(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		var rows [][]string
+		for _, favorite := range favorites {
+			rows = append(rows, []string{favorite.Name, favorite.Path, favorite.Status})
+		}
+		printOutput(favorites, []string{"Name", "Path", "Status"}, rows, nil)
+	}
+}
+
+// favoriteResult is the outcome of an operation on a favorite
+type favoriteResult struct {
+	Name   string `json:"name,omitempty"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newFavoriteResult(name string, folder string, action string, err error) favoriteResult {
+	result := favoriteResult{Name: name, Path: folder, Action: action}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// outputFavoriteResults writes favorite results in structured formats,
+// tables are already printed as messages
+func outputFavoriteResults(results []favoriteResult) {
+	var rows [][]string
+	for _, result := range results {
+		rows = append(rows, []string{result.Name, result.Path, result.Action, result.Error})
+	}
+	printOutput(results, []string{"Name", "Path", "Action", "Error"}, rows, func() {})
+}
+
+func listMounts(client *synoclient.Client, args []string) {
+	flags := newFlagSet("mounts")
+	var types stringList
+	flags.Var(&types, "type", "Only list mounts of type cifs, nfs or iso (repeatable)")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+	if len(types) == 0 {
+		types = stringList{"cifs", "nfs", "iso"}
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var mounts []mount
+	var rows [][]string
+	for _, folderType := range types {
+		folders, _, err := client.ListVirtualFolders(folderType, synoclient.ListOptions{
+			Additional: []string{"real_path"},
+		})
+		if err != nil {
+			printErrorf("Could not list %v mounts: %v\n", folderType, err)
+			continue
+		}
+		for _, folder := range folders {
+			mounts = append(mounts, mount{Type: folderType, Name: folder.Name, Path: folder.Path, RealPath: folder.RealPath})
+			rows = append(rows, []string{folderType, folder.Name, folder.Path, folder.RealPath})
+		}
+	}
+	printOutput(mounts, []string{"Type", "Name", "Path", "Real path"}, rows, nil)
+}
+
+// mount is a remote folder or ISO image mounted on the NAS
+type mount struct {
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	RealPath string `json:"real_path"`
+}
+
+// resolveFavorite replaces a leading favorite name in destination with the
+// favorite's folder, e.g. Movies/file.mkv. A bare favorite name keeps the
+// file name. Absolute paths are returned unchanged.
+func resolveFavorite(client *synoclient.Client, destination string, name string) (string, error) {
+	if strings.HasPrefix(destination, "/") {
+		return destination, nil
+	}
+
+	favorites, _, err := client.ListFavorites(0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.SplitN(destination, "/", 2)
+	for _, favorite := range favorites {
+		if favorite.Name != parts[0] {
+			continue
+		}
+		if len(parts) == 1 || parts[1] == "" {
+			return path.Join(favorite.Path, name), nil
+		}
+		return path.Join(favorite.Path, parts[1]), nil
+	}
+	return "", fmt.Errorf("No favorite named %v", parts[0])
+}
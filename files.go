@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func copyFiles(client *synoclient.Client, args []string) {
+	transferFiles(client, "cp", args)
+}
+
+func moveFiles(client *synoclient.Client, args []string) {
+	transferFiles(client, "mv", args)
+}
+
+func transferFiles(client *synoclient.Client, name string, args []string) {
+	flags := newFlagSet(name)
+	overwrite := flags.Bool("overwrite", false, "Overwrite existing files")
+	skip := flags.Bool("skip", false, "Skip existing files")
+	accurate := flags.Bool("accurate", false, "Calculate accurate progress")
+
+	positional := parseArgs(flags, args)
+	if len(positional) < 2 || (*overwrite && *skip) {
+		flags.Usage()
+		return
+	}
+	paths, destination := positional[:len(positional)-1], positional[len(positional)-1]
+
+	opts := synoclient.CopyMoveOptions{AccurateProgress: *accurate}
+	if *overwrite {
+		opts.Conflict = synoclient.ConflictOverwrite
+	}
+	if *skip {
+		opts.Conflict = synoclient.ConflictSkip
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var task *synoclient.FileTask
+	if name == "cp" {
+		task, err = client.Copy(paths, destination, opts)
+	} else {
+		task, err = client.Move(paths, destination, opts)
+	}
+	if err == nil {
+		err = waitFileTask(task)
+	}
+
+	action := "copied"
+	if name == "mv" {
+		action = "moved"
+	}
+	if err != nil {
+		printError(err)
+	} else if name == "cp" {
+		printMessage("Files copied.\n")
+	} else {
+		printMessage("Files moved.\n")
+	}
+	outputFileResults(pathResults(paths, action, destination, err))
+}
+
+// fileResult is the outcome of a file operation on a path
+type fileResult struct {
+	Path        string `json:"path"`
+	Action      string `json:"action"`
+	Destination string `json:"destination,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+func newFileResult(path string, action string, destination string, err error) fileResult {
+	result := fileResult{Path: path, Action: action, Destination: destination}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// pathResults are the results of a single operation on several paths
+func pathResults(paths []string, action string, destination string, err error) []fileResult {
+	var results []fileResult
+	for _, p := range paths {
+		results = append(results, newFileResult(p, action, destination, err))
+	}
+	return results
+}
+
+// outputFileResults writes file results in structured formats, tables are
+// already printed as messages
+func outputFileResults(results []fileResult) {
+	var rows [][]string
+	for _, result := range results {
+		rows = append(rows, []string{result.Path, result.Action, result.Destination, result.Error})
+	}
+	printOutput(results, []string{"Path", "Action", "Destination", "Error"}, rows, func() {})
+}
+
+// waitFileTask waits for a background task printing its progress. The
+// task is stopped on interrupt.
+func waitFileTask(task *synoclient.FileTask) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	_, err := task.Watch(ctx, func(status *synoclient.FileTaskStatus) {
+		fmt.Fprintf(messageOutput(), "\r%3.0f%% %-70.70v", status.Progress*100, status.ProcessingPath)
+	})
+	fmt.Fprintln(messageOutput())
+
+	if errors.Is(err, context.Canceled) {
+		if err := task.Stop(); err != nil {
+			return err
+		}
+		return errors.New("Task stopped")
+	}
+	return err
+}
+
+// waitResultTask waits for a task which keeps its result on the NAS until
+// stopped, e.g. MD5 and DirSize, and stops it when done or interrupted
+func waitResultTask(task *synoclient.FileTask) (*synoclient.FileTaskStatus, error) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	status, err := task.Wait(ctx)
+	// the result is already read, failing to stop does not change it
+	stopErr := task.Stop()
+	if errors.Is(err, context.Canceled) {
+		if stopErr != nil {
+			return nil, stopErr
+		}
+		return nil, errors.New("Task stopped")
+	}
+	return status, err
+}
+
+func makeFolders(client *synoclient.Client, args []string) {
+	flags := newFlagSet("mkdir")
+	parents := flags.Bool("p", false, "Create missing parent folders, no error if existing")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var results []fileResult
+	for _, folder := range positional {
+		folder = path.Clean(folder)
+		_, err := client.CreateFolder(path.Dir(folder), path.Base(folder), *parents)
+		if err != nil {
+			printErrorf("Could not create %v: %v\n", folder, err)
+		} else {
+			printMessage("Created %v\n", folder)
+		}
+		results = append(results, newFileResult(folder, "created", "", err))
+	}
+	outputFileResults(results)
+}
+
+func renameFile(client *synoclient.Client, args []string) {
+	flags := newFlagSet("fs rename")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 || strings.Contains(positional[1], "/") {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	renamed, err := client.RenameFile(positional[0], positional[1])
+	if err != nil {
+		printErrorf("Could not rename %v: %v\n", positional[0], err)
+	} else {
+		printMessage("Renamed to %v\n", renamed)
+	}
+	outputFileResults([]fileResult{newFileResult(positional[0], "renamed", renamed, err)})
+}
+
+func removeFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("rm")
+	recursive := flags.Bool("r", false, "Remove folders and their content")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	task, err := client.Delete(positional, *recursive)
+	if err == nil {
+		err = waitFileTask(task)
+	}
+	if err != nil {
+		printError(err)
+	} else {
+		printMessage("Files removed.\n")
+	}
+	outputFileResults(pathResults(positional, "removed", "", err))
+}
+
+func statFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("stat")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var infos []synoclient.FileInfo
+	for _, file := range positional {
+		info, err := client.Stat(file)
+		if err != nil {
+			printErrorf("Could not stat %v: %v\n", file, err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	printOutput(infos, fileHeader, fileRows(infos), func() {
+		for _, info := range infos {
+			printFileInfo(info)
+		}
+	})
+}
+
+func printFileInfo(file synoclient.FileInfo) {
+	const maxlen = len("Real path: ")
+	fmt.Printf("%v%v\n", padTitle("Path:", maxlen), file.Path)
+	fmt.Printf("%v%v\n", padTitle("Real path:", maxlen), file.RealPath)
+	if file.IsDir {
+		fmt.Printf("%v%v\n", padTitle("Type:", maxlen), "dir")
+	} else {
+		fmt.Printf("%v%v\n", padTitle("Type:", maxlen), "file")
+	}
+	if file.Type != "" {
+		fmt.Printf("%v%v\n", padTitle("Format:", maxlen), file.Type)
+	}
+	if file.MountPointType != "" {
+		fmt.Printf("%v%v\n", padTitle("Mount:", maxlen), file.MountPointType)
+	}
+	fmt.Printf("%v%v (%v B)\n", padTitle("Size:", maxlen), ByteCountSI(file.Size), file.Size)
+	fmt.Printf("%v%v (%v)\n", padTitle("Access:", maxlen), formatPerm(file), file.Perm.Posix)
+	fmt.Printf("%v%v/%v\n", padTitle("Owner:", maxlen), file.Owner.User, file.Owner.Group)
+	fmt.Printf("%v%v\n", padTitle("Modified:", maxlen), file.Time.Mtime)
+	fmt.Printf("%v%v\n", padTitle("Changed:", maxlen), file.Time.Ctime)
+	fmt.Printf("%v%v\n", padTitle("Accessed:", maxlen), file.Time.Atime)
+	fmt.Printf("%v%v\n", padTitle("Created:", maxlen), file.Time.Crtime)
+}
+
+func diskUsage(client *synoclient.Client, args []string) {
+	flags := newFlagSet("du")
+	bytes := flags.Bool("b", false, "Print sizes in bytes")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var usages []dirUsage
+	for _, folder := range positional {
+		task, err := client.DirSize([]string{folder})
+		if err != nil {
+			printErrorf("Could not size %v: %v\n", folder, err)
+			continue
+		}
+		status, err := waitResultTask(task)
+		if err != nil {
+			printErrorf("Could not size %v: %v\n", folder, err)
+			continue
+		}
+
+		usages = append(usages, dirUsage{Path: folder, TotalSize: status.TotalSize, NumFile: status.NumFile, NumDir: status.NumDir})
+	}
+
+	var rows [][]string
+	for _, usage := range usages {
+		rows = append(rows, []string{
+			usage.Path,
+			strconv.FormatInt(usage.TotalSize, 10),
+			strconv.FormatInt(usage.NumFile, 10),
+			strconv.FormatInt(usage.NumDir, 10),
+		})
+	}
+	printOutput(usages, []string{"Path", "Size", "Files", "Folders"}, rows, func() {
+		for _, usage := range usages {
+			size := ByteCountSI(usage.TotalSize)
+			if *bytes {
+				size = strconv.FormatInt(usage.TotalSize, 10)
+			}
+			fmt.Printf("%-10v %v (%v files, %v folders)\n", size, usage.Path, usage.NumFile, usage.NumDir)
+		}
+	})
+}
+
+// dirUsage is the result of du
+type dirUsage struct {
+	Path      string `json:"path"`
+	TotalSize int64  `json:"total_size"`
+	NumFile   int64  `json:"num_file"`
+	NumDir    int64  `json:"num_dir"`
+}
+
+func md5Files(client *synoclient.Client, args []string) {
+	flags := newFlagSet("md5sum")
+	check := flags.String("check", "", "Compare a single remote file with this local file")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 || (*check != "" && len(positional) != 1) {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	if *check != "" {
+		same, err := sameChecksum(client, positional[0], *check)
+		if err != nil {
+			printError(err)
+			return
+		}
+		result := checksumResult{Path: positional[0], Local: *check, Match: same}
+		printOutput(result, []string{"Path", "Local", "Match"}, [][]string{{result.Path, result.Local, strconv.FormatBool(same)}}, func() {
+			if same {
+				fmt.Printf("%v: OK\n", positional[0])
+			} else {
+				fmt.Printf("%v: FAILED\n", positional[0])
+			}
+		})
+		if !same {
+			setExitStatus(1)
+		}
+		return
+	}
+
+	var checksums []fileChecksum
+	for _, file := range positional {
+		task, err := client.MD5(file)
+		if err != nil {
+			printErrorf("Could not hash %v: %v\n", file, err)
+			continue
+		}
+		status, err := waitResultTask(task)
+		if err != nil {
+			printErrorf("Could not hash %v: %v\n", file, err)
+			continue
+		}
+		checksums = append(checksums, fileChecksum{Path: file, MD5: status.MD5})
+	}
+
+	var rows [][]string
+	for _, checksum := range checksums {
+		rows = append(rows, []string{checksum.Path, checksum.MD5})
+	}
+	printOutput(checksums, []string{"Path", "MD5"}, rows, func() {
+		for _, checksum := range checksums {
+			fmt.Printf("%v  %v\n", checksum.MD5, checksum.Path)
+		}
+	})
+}
+
+// fileChecksum is a result of md5sum
+type fileChecksum struct {
+	Path string `json:"path"`
+	MD5  string `json:"md5"`
+}
+
+// checksumResult is the result of md5sum -check
+type checksumResult struct {
+	Path  string `json:"path"`
+	Local string `json:"local"`
+	Match bool   `json:"match"`
+}
+
+func checkAccess(client *synoclient.Client, args []string) {
+	flags := newFlagSet("access")
+	overwrite := flags.Bool("overwrite", false, "Check permission to overwrite an existing file")
+
+	positional := parseArgs(flags, args)
+	if len(positional) < 1 || len(positional) > 2 {
+		flags.Usage()
+		return
+	}
+	folder, filename := positional[0], ".synogo"
+	if len(positional) == 2 {
+		filename = positional[1]
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	result := accessResult{Path: folder, Filename: filename, Writable: true}
+	if err := client.CheckPermission(folder, filename, *overwrite, !*overwrite); err != nil {
+		printErrorf("No write permission on %v: %v\n", folder, err)
+		result.Writable, result.Error = false, err.Error()
+	}
+	printOutput(result, []string{"Path", "Filename", "Writable", "Error"}, [][]string{{
+		result.Path, result.Filename, strconv.FormatBool(result.Writable), result.Error,
+	}}, func() {
+		if result.Writable {
+			fmt.Printf("Write permission on %v granted.\n", folder)
+		}
+	})
+}
+
+// accessResult is the result of access
+type accessResult struct {
+	Path     string `json:"path"`
+	Filename string `json:"filename"`
+	Writable bool   `json:"writable"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func TestMD5Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Form.Get("api") + "." + r.Form.Get("method") {
+		case "SYNO.API.Auth.login":
+			w.Write([]byte(`{"success":true,"data":{"sid":"sid"}}`))
+		case "SYNO.FileStation.MD5.start":
+			w.Write([]byte(`{"success":true,"data":{"taskid":"MD5_1"}}`))
+		case "SYNO.FileStation.MD5.status":
+			// MD5 of "hello\n"
+			w.Write([]byte(`{"success":true,"data":{"finished":true,"md5":"b1946ac92492d2347c6235b4d2611184"}}`))
+		default:
+			w.Write([]byte(`{"success":true}`))
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &synoclient.Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	dir, err := ioutil.TempDir("", "synogo-md5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "match", content: "hello\n", want: 0},
+		{name: "mismatch", content: "hello world\n", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := filepath.Join(dir, tt.name)
+			if err := ioutil.WriteFile(local, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			exitStatus = 0
+			defer func() { exitStatus = 0 }()
+
+			md5Files(client, []string{"-check", local, "/video/a.txt"})
+			if exitStatus != tt.want {
+				t.Errorf("exit status = %v, want %v", exitStatus, tt.want)
+			}
+		})
+	}
+}
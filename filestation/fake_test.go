@@ -0,0 +1,234 @@
+package filestation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// fakeDSM is an in-memory FileStation served by entry.cgi and auth.cgi
+type fakeDSM struct {
+	mu    sync.Mutex
+	files map[string]*fakeFile
+	tasks int
+}
+
+type fakeFile struct {
+	data  []byte
+	dir   bool
+	mtime time.Time
+}
+
+// newFakeDSM serves a NAS with the shares video and downloads and returns
+// a logged in client
+func newFakeDSM(t *testing.T) (*fakeDSM, *synoclient.Client) {
+	t.Helper()
+	dsm := &fakeDSM{files: map[string]*fakeFile{}}
+	mtime := time.Unix(1600000000, 0)
+	dsm.mkdirAll("/video/movies", mtime)
+	dsm.mkdirAll("/downloads", mtime)
+	dsm.files["/video/a.txt"] = &fakeFile{data: []byte("hello"), mtime: mtime}
+	dsm.files["/video/movies/b.txt"] = &fakeFile{data: []byte("bye"), mtime: mtime}
+
+	server := httptest.NewServer(dsm)
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+
+	client := &synoclient.Client{Host: u.Host, Scheme: "http", Username: "admin", Timeout: 10}
+	if _, err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return dsm, client
+}
+
+// file returns the content of a file or nil if it does not exist
+func (dsm *fakeDSM) file(p string) []byte {
+	dsm.mu.Lock()
+	defer dsm.mu.Unlock()
+	if f := dsm.files[p]; f != nil && !f.dir {
+		return f.data
+	}
+	return nil
+}
+
+func (dsm *fakeDSM) mkdirAll(p string, mtime time.Time) {
+	for ; p != "/"; p = path.Dir(p) {
+		if dsm.files[p] == nil {
+			dsm.files[p] = &fakeFile{dir: true, mtime: mtime}
+		}
+	}
+}
+
+func (dsm *fakeDSM) children(parent string) []string {
+	var names []string
+	for p := range dsm.files {
+		if path.Dir(p) == parent {
+			names = append(names, p)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (dsm *fakeDSM) move(from string, to string) {
+	for p, f := range dsm.files {
+		if p == from || strings.HasPrefix(p, from+"/") {
+			delete(dsm.files, p)
+			dsm.files[to+strings.TrimPrefix(p, from)] = f
+		}
+	}
+}
+
+func (dsm *fakeDSM) info(p string) map[string]interface{} {
+	f := dsm.files[p]
+	return map[string]interface{}{
+		"path":  p,
+		"name":  path.Base(p),
+		"isdir": f.dir,
+		"additional": map[string]interface{}{
+			"size": len(f.data),
+			"time": map[string]interface{}{"mtime": f.mtime.Unix()},
+			"perm": map[string]interface{}{"posix": 755},
+		},
+	}
+}
+
+func (dsm *fakeDSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		r.ParseMultipartForm(32 << 20)
+	} else {
+		r.ParseForm()
+	}
+	dsm.mu.Lock()
+	defer dsm.mu.Unlock()
+
+	paths := func(param string) []string {
+		var list []string
+		if json.Unmarshal([]byte(r.Form.Get(param)), &list) != nil {
+			list = []string{r.Form.Get(param)}
+		}
+		return list
+	}
+
+	switch r.Form.Get("api") + "." + r.Form.Get("method") {
+	case "SYNO.API.Auth.login":
+		dsmSuccess(w, map[string]interface{}{"sid": "sid"})
+	case "SYNO.API.Auth.logout":
+		dsmSuccess(w, nil)
+	case "SYNO.FileStation.List.list_share":
+		var shares []interface{}
+		for _, p := range dsm.children("/") {
+			shares = append(shares, dsm.info(p))
+		}
+		dsmSuccess(w, map[string]interface{}{"shares": shares, "total": len(shares), "offset": 0})
+	case "SYNO.FileStation.List.list":
+		folder := r.Form.Get("folder_path")
+		if f := dsm.files[folder]; f == nil || !f.dir {
+			dsmFailure(w, 408)
+			return
+		}
+		var files []interface{}
+		for _, p := range dsm.children(folder) {
+			files = append(files, dsm.info(p))
+		}
+		dsmSuccess(w, map[string]interface{}{"files": files, "total": len(files), "offset": 0})
+	case "SYNO.FileStation.List.getinfo":
+		var files []interface{}
+		for _, p := range paths("path") {
+			if dsm.files[p] == nil {
+				files = append(files, map[string]interface{}{"code": 408, "path": p, "name": path.Base(p)})
+			} else {
+				files = append(files, dsm.info(p))
+			}
+		}
+		dsmSuccess(w, map[string]interface{}{"files": files})
+	case "SYNO.FileStation.Download.download":
+		p := r.Form.Get("path")
+		f := dsm.files[p]
+		if f == nil || f.dir {
+			dsmFailure(w, 408)
+			return
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="`+path.Base(p)+`"`)
+		http.ServeContent(w, r, "", f.mtime, bytes.NewReader(f.data))
+	case "SYNO.FileStation.Upload.upload":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			dsmFailure(w, 401)
+			return
+		}
+		defer file.Close()
+		folder := r.Form.Get("path")
+		if dsm.files[folder] == nil {
+			dsmFailure(w, 408)
+			return
+		}
+		data, _ := ioutil.ReadAll(file)
+		dsm.files[path.Join(folder, header.Filename)] = &fakeFile{data: data, mtime: time.Now()}
+		dsmSuccess(w, nil)
+	case "SYNO.FileStation.CreateFolder.create":
+		folder := r.Form.Get("folder_path")
+		if dsm.files[folder] == nil {
+			dsmFailure(w, 408)
+			return
+		}
+		p := path.Join(folder, r.Form.Get("name"))
+		dsm.mkdirAll(p, time.Now())
+		dsmSuccess(w, map[string]interface{}{"folders": []interface{}{dsm.info(p)}})
+	case "SYNO.FileStation.Rename.rename":
+		p := r.Form.Get("path")
+		if dsm.files[p] == nil {
+			dsmFailure(w, 408)
+			return
+		}
+		renamed := path.Join(path.Dir(p), r.Form.Get("name"))
+		dsm.move(p, renamed)
+		dsmSuccess(w, map[string]interface{}{"files": []interface{}{dsm.info(renamed)}})
+	case "SYNO.FileStation.CopyMove.start", "SYNO.FileStation.Delete.start":
+		// tasks finish right away
+		for _, p := range paths("path") {
+			if dsm.files[p] == nil {
+				dsmFailure(w, 408)
+				return
+			}
+			if r.Form.Get("api") == "SYNO.FileStation.Delete" {
+				dsm.move(p, "/.deleted"+p)
+			} else {
+				dsm.move(p, path.Join(r.Form.Get("dest_folder_path"), path.Base(p)))
+			}
+		}
+		for p := range dsm.files {
+			if strings.HasPrefix(p, "/.deleted/") {
+				delete(dsm.files, p)
+			}
+		}
+		dsm.tasks++
+		dsmSuccess(w, map[string]interface{}{"taskid": fmt.Sprintf("FileStation_%d", dsm.tasks)})
+	case "SYNO.FileStation.CopyMove.status", "SYNO.FileStation.Delete.status":
+		dsmSuccess(w, map[string]interface{}{"finished": true, "progress": 1})
+	default:
+		dsmFailure(w, 103)
+	}
+}
+
+func dsmSuccess(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": data})
+}
+
+func dsmFailure(w http.ResponseWriter, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"success":false,"error":{"code":%d}}`, code)
+}
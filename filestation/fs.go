@@ -0,0 +1,267 @@
+// Package filestation provides io/fs access to Synology NAS shares
+package filestation
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// FS implements fs.FS, fs.ReadDirFS and fs.StatFS on top of FileStation.
+// Names are relative to the root folder, e.g. "video/movie.mkv" with root
+// "/", which is the list of shared folders.
+type FS struct {
+	client *synoclient.Client
+	root   string
+}
+
+// New returns a file system rooted at root using a logged in client
+func New(client *synoclient.Client, root string) *FS {
+	return &FS{client: client, root: path.Clean("/" + root)}
+}
+
+func (fsys *FS) fullPath(op string, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return path.Join(fsys.root, name), nil
+}
+
+// Open opens the named file or folder
+func (fsys *FS) Open(name string) (fs.File, error) {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: unwrapPathError(err)}
+	}
+
+	if info.IsDir() {
+		return &dir{fsys: fsys, name: name, info: info}, nil
+	}
+	return &file{fsys: fsys, name: name, info: info}, nil
+}
+
+// Stat returns info of the named file or folder
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	fullPath, err := fsys.fullPath("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the root of the NAS is not a real folder
+	if fullPath == "/" {
+		return rootInfo{}, nil
+	}
+
+	info, err := fsys.client.Stat(fullPath)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: mapError(err)}
+	}
+	return fileInfo{info}, nil
+}
+
+// ReadDir returns the entries of the named folder sorted by name
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	fullPath, err := fsys.fullPath("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+
+	// listing the root lists shared folders
+	if fullPath == "/" {
+		fullPath = ""
+	}
+
+	var entries []fs.DirEntry
+	it := fsys.client.NewFileIterator(fullPath, synoclient.ListOptions{
+		SortBy:     "name",
+		Additional: synoclient.DefaultFileAdditional,
+	})
+	for it.Next() {
+		entries = append(entries, dirEntry{fileInfo{it.File()}})
+	}
+	if err := it.Err(); err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: mapError(err)}
+	}
+
+	// FileStation sorts case insensitive
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// mapError converts FileStation errors to fs errors where possible
+func mapError(err error) error {
+	switch synoclient.ErrorCode(err) {
+	case 408:
+		return fs.ErrNotExist
+	case 105, 402, 403, 404, 405, 407:
+		return fs.ErrPermission
+	case 414:
+		return fs.ErrExist
+	}
+	return err
+}
+
+func unwrapPathError(err error) error {
+	if pathErr, ok := err.(*fs.PathError); ok {
+		return pathErr.Err
+	}
+	return err
+}
+
+// fileInfo implements fs.FileInfo, Sys returns the synoclient.FileInfo
+type fileInfo struct {
+	info synoclient.FileInfo
+}
+
+func (fi fileInfo) Name() string       { return fi.info.Name }
+func (fi fileInfo) Size() int64        { return fi.info.Size }
+func (fi fileInfo) ModTime() time.Time { return fi.info.Time.Mtime }
+func (fi fileInfo) IsDir() bool        { return fi.info.IsDir }
+func (fi fileInfo) Sys() interface{}   { return fi.info }
+
+// Mode converts posix permissions, e.g. 755 reported as decimal
+func (fi fileInfo) Mode() fs.FileMode {
+	var mode fs.FileMode
+	for posix, shift := fi.info.Perm.Posix, uint(0); posix > 0 && shift < 9; posix, shift = posix/10, shift+3 {
+		mode |= fs.FileMode(posix%10) << shift
+	}
+	if fi.info.IsDir {
+		mode |= fs.ModeDir
+	}
+	return mode
+}
+
+// rootInfo is the info of the NAS root holding the shared folders
+type rootInfo struct{}
+
+func (rootInfo) Name() string       { return "." }
+func (rootInfo) Size() int64        { return 0 }
+func (rootInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (rootInfo) ModTime() time.Time { return time.Time{} }
+func (rootInfo) IsDir() bool        { return true }
+func (rootInfo) Sys() interface{}   { return nil }
+
+// dirEntry implements fs.DirEntry
+type dirEntry struct {
+	info fileInfo
+}
+
+func (de dirEntry) Name() string               { return de.info.Name() }
+func (de dirEntry) IsDir() bool                { return de.info.IsDir() }
+func (de dirEntry) Type() fs.FileMode          { return de.info.Mode().Type() }
+func (de dirEntry) Info() (fs.FileInfo, error) { return de.info, nil }
+
+// dir is an open folder
+type dir struct {
+	fsys    *FS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *dir) Close() error               { return nil }
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// file is an open file, its content is downloaded on first read
+type file struct {
+	fsys     *FS
+	name     string
+	info     fs.FileInfo
+	download *synoclient.Download
+	offset   int64
+}
+
+func (f *file) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *file) Read(b []byte) (int, error) {
+	if f.offset >= f.info.Size() {
+		return 0, io.EOF
+	}
+
+	if f.download == nil {
+		download, err := f.fsys.client.Download(path.Join(f.fsys.root, f.name), f.offset)
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: mapError(err)}
+		}
+		// skip to the offset if the range was not honored
+		if download.Offset < f.offset {
+			if _, err := io.CopyN(io.Discard, download, f.offset-download.Offset); err != nil {
+				download.Close()
+				return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+			}
+		}
+		f.download = download
+	}
+
+	n, err := f.download.Read(b)
+	f.offset += int64(n)
+	return n, err
+}
+
+// Seek implements io.Seeker, the download is restarted at the new offset
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset != f.offset && f.download != nil {
+		f.download.Close()
+		f.download = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *file) Close() error {
+	if f.download == nil {
+		return nil
+	}
+	err := f.download.Close()
+	f.download = nil
+	return err
+}
@@ -0,0 +1,33 @@
+package filestation
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	_, client := newFakeDSM(t)
+
+	fsys := New(client, "/")
+	if err := fstest.TestFS(fsys, "video/a.txt", "video/movies/b.txt", "downloads"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "video/a.txt")
+	if err != nil || string(data) != "hello" {
+		t.Errorf("ReadFile = %q, %v, want %q", data, err, "hello")
+	}
+	if _, err := fsys.Stat("video/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat of missing file = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestFSRoot(t *testing.T) {
+	_, client := newFakeDSM(t)
+
+	if err := fstest.TestFS(New(client, "/video"), "a.txt", "movies/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
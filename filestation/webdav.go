@@ -0,0 +1,252 @@
+package filestation
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+	"golang.org/x/net/webdav"
+)
+
+// WebDAV implements webdav.FileSystem on top of FileStation. Files opened
+// for writing are buffered in a local temporary file and uploaded on close.
+type WebDAV struct {
+	fsys *FS
+}
+
+// NewWebDAV returns a WebDAV file system rooted at root using a logged in
+// client
+func NewWebDAV(client *synoclient.Client, root string) *WebDAV {
+	return &WebDAV{fsys: New(client, root)}
+}
+
+// name converts a WebDAV name, e.g. /video/movie.mkv, to an fs name
+func (w *WebDAV) name(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// Mkdir creates a folder, its parent must exist
+func (w *WebDAV) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	name = w.name(name)
+	fullPath, err := w.fsys.fullPath("mkdir", name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.fsys.Stat(name); err == nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if _, err := w.fsys.client.CreateFolder(path.Dir(fullPath), path.Base(fullPath), false); err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: mapError(err)}
+	}
+	return nil
+}
+
+// OpenFile opens a file or folder for reading or a file for writing
+func (w *WebDAV) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	name = w.name(name)
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC) == 0 {
+		f, err := w.fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		if d, ok := f.(*dir); ok {
+			return &webdavDir{dir: d}, nil
+		}
+		return &webdavFile{file: f.(*file)}, nil
+	}
+
+	info, err := w.fsys.Stat(name)
+	exists := err == nil
+	switch {
+	case err == nil && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case err == nil && info.IsDir():
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	case err != nil && (flag&os.O_CREATE == 0 || !os.IsNotExist(err)):
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile("", "synogo-webdav-")
+	if err != nil {
+		return nil, err
+	}
+	upload := &uploadFile{fsys: w.fsys, name: name, tmp: tmp}
+
+	// keep the existing content unless it is truncated
+	if exists && flag&os.O_TRUNC == 0 {
+		existing, err := w.fsys.Open(name)
+		if err == nil {
+			_, err = io.Copy(tmp, existing)
+			existing.Close()
+		}
+		if err == nil && flag&os.O_APPEND == 0 {
+			_, err = tmp.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			upload.discard()
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// RemoveAll deletes a file or folder including its content
+func (w *WebDAV) RemoveAll(ctx context.Context, name string) error {
+	name = w.name(name)
+	fullPath, err := w.fsys.fullPath("remove", name)
+	if err != nil {
+		return err
+	}
+	if name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+	}
+
+	task, err := w.fsys.client.Delete([]string{fullPath}, true)
+	if err == nil {
+		_, err = task.Wait(ctx)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: mapError(err)}
+	}
+	return nil
+}
+
+// Rename renames and moves a file or folder, an existing target is not
+// overwritten
+func (w *WebDAV) Rename(ctx context.Context, oldName string, newName string) error {
+	oldName, newName = w.name(oldName), w.name(newName)
+	oldPath, err := w.fsys.fullPath("rename", oldName)
+	if err != nil {
+		return err
+	}
+	newPath, err := w.fsys.fullPath("rename", newName)
+	if err != nil {
+		return err
+	}
+
+	if path.Dir(oldPath) != path.Dir(newPath) {
+		task, err := w.fsys.client.Move([]string{oldPath}, path.Dir(newPath), synoclient.CopyMoveOptions{})
+		if err == nil {
+			_, err = task.Wait(ctx)
+		}
+		if err != nil {
+			return &fs.PathError{Op: "rename", Path: oldName, Err: mapError(err)}
+		}
+		oldPath = path.Join(path.Dir(newPath), path.Base(oldPath))
+	}
+
+	if path.Base(oldPath) != path.Base(newPath) {
+		if _, err := w.fsys.client.RenameFile(oldPath, path.Base(newPath)); err != nil {
+			return &fs.PathError{Op: "rename", Path: oldName, Err: mapError(err)}
+		}
+	}
+	return nil
+}
+
+// Stat returns info of a file or folder
+func (w *WebDAV) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	return w.fsys.Stat(w.name(name))
+}
+
+// webdavFile is a file opened for reading
+type webdavFile struct {
+	*file
+}
+
+func (f *webdavFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: fs.ErrInvalid}
+}
+
+func (f *webdavFile) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+}
+
+// webdavDir is an open folder
+type webdavDir struct {
+	*dir
+}
+
+func (d *webdavDir) Readdir(count int) ([]fs.FileInfo, error) {
+	entries, err := d.ReadDir(count)
+	var infos []fs.FileInfo
+	for _, entry := range entries {
+		info, _ := entry.Info()
+		infos = append(infos, info)
+	}
+	return infos, err
+}
+
+func (d *webdavDir) Seek(offset int64, whence int) (int64, error) {
+	return 0, &fs.PathError{Op: "seek", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *webdavDir) Write([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "write", Path: d.name, Err: fs.ErrInvalid}
+}
+
+// uploadFile is a file opened for writing, it is uploaded on close
+type uploadFile struct {
+	fsys *FS
+	name string
+	tmp  *os.File
+}
+
+func (f *uploadFile) Read(b []byte) (int, error)                   { return f.tmp.Read(b) }
+func (f *uploadFile) Write(b []byte) (int, error)                  { return f.tmp.Write(b) }
+func (f *uploadFile) Seek(offset int64, whence int) (int64, error) { return f.tmp.Seek(offset, whence) }
+
+func (f *uploadFile) Readdir(count int) ([]fs.FileInfo, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: fs.ErrInvalid}
+}
+
+func (f *uploadFile) Stat() (fs.FileInfo, error) {
+	info, err := f.tmp.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return uploadInfo{FileInfo: info, name: path.Base(f.name)}, nil
+}
+
+func (f *uploadFile) Close() error {
+	defer f.discard()
+
+	info, err := f.tmp.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	fullPath := path.Join(f.fsys.root, f.name)
+	err = f.fsys.client.Upload(path.Dir(fullPath), path.Base(fullPath), f.tmp, info.Size(), synoclient.UploadOptions{
+		Conflict: synoclient.ConflictOverwrite,
+	})
+	if err != nil {
+		return &fs.PathError{Op: "write", Path: f.name, Err: mapError(err)}
+	}
+	return nil
+}
+
+func (f *uploadFile) discard() {
+	f.tmp.Close()
+	os.Remove(f.tmp.Name())
+}
+
+// uploadInfo is the info of a file being written, named like the target
+type uploadInfo struct {
+	fs.FileInfo
+	name string
+}
+
+func (fi uploadInfo) Name() string { return fi.name }
@@ -0,0 +1,134 @@
+package filestation
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/webdav"
+)
+
+func newWebDAVServer(t *testing.T) (*fakeDSM, *httptest.Server) {
+	t.Helper()
+	dsm, client := newFakeDSM(t)
+	server := httptest.NewServer(&webdav.Handler{
+		FileSystem: NewWebDAV(client, "/"),
+		LockSystem: webdav.NewMemLS(),
+	})
+	t.Cleanup(server.Close)
+	return dsm, server
+}
+
+func doWebDAV(t *testing.T, method string, url string, body string, header map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(data)
+}
+
+func TestWebDAVPropfind(t *testing.T) {
+	_, server := newWebDAVServer(t)
+
+	resp, body := doWebDAV(t, "PROPFIND", server.URL+"/video/", "", map[string]string{"Depth": "1"})
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND status = %v, want %v", resp.StatusCode, http.StatusMultiStatus)
+	}
+	for _, href := range []string{"/video/", "/video/a.txt", "/video/movies/"} {
+		if !strings.Contains(body, "<D:href>"+href+"</D:href>") {
+			t.Errorf("PROPFIND response misses %v:\n%v", href, body)
+		}
+	}
+	if !strings.Contains(body, "<D:getcontentlength>5</D:getcontentlength>") {
+		t.Errorf("PROPFIND response misses the size of a.txt:\n%v", body)
+	}
+}
+
+func TestWebDAVGetRange(t *testing.T) {
+	_, server := newWebDAVServer(t)
+
+	resp, body := doWebDAV(t, "GET", server.URL+"/video/a.txt", "", nil)
+	if resp.StatusCode != http.StatusOK || body != "hello" {
+		t.Errorf("GET = %v %q, want %v %q", resp.StatusCode, body, http.StatusOK, "hello")
+	}
+
+	resp, body = doWebDAV(t, "GET", server.URL+"/video/a.txt", "", map[string]string{"Range": "bytes=1-3"})
+	if resp.StatusCode != http.StatusPartialContent || body != "ell" {
+		t.Errorf("GET with range = %v %q, want %v %q", resp.StatusCode, body, http.StatusPartialContent, "ell")
+	}
+	if got := resp.Header.Get("Content-Range"); got != "bytes 1-3/5" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes 1-3/5")
+	}
+}
+
+func TestWebDAVPut(t *testing.T) {
+	dsm, server := newWebDAVServer(t)
+
+	resp, _ := doWebDAV(t, "PUT", server.URL+"/video/new.txt", "new content", nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT status = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+	if got := string(dsm.file("/video/new.txt")); got != "new content" {
+		t.Errorf("uploaded content = %q, want %q", got, "new content")
+	}
+
+	resp, _ = doWebDAV(t, "PUT", server.URL+"/video/a.txt", "replaced", nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT over existing file status = %v, want %v", resp.StatusCode, http.StatusCreated)
+	}
+	if got := string(dsm.file("/video/a.txt")); got != "replaced" {
+		t.Errorf("replaced content = %q, want %q", got, "replaced")
+	}
+}
+
+func TestWebDAVMove(t *testing.T) {
+	dsm, server := newWebDAVServer(t)
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{name: "rename", from: "/video/a.txt", to: "/video/d.txt"},
+		{name: "move", from: "/video/d.txt", to: "/downloads/d.txt"},
+		{name: "move and rename", from: "/downloads/d.txt", to: "/video/movies/c.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := doWebDAV(t, "MOVE", server.URL+tt.from, "", map[string]string{"Destination": server.URL + tt.to})
+			if resp.StatusCode != http.StatusCreated {
+				t.Fatalf("MOVE status = %v, want %v", resp.StatusCode, http.StatusCreated)
+			}
+			if dsm.file(tt.from) != nil {
+				t.Errorf("%v still exists", tt.from)
+			}
+			if got := string(dsm.file(tt.to)); got != "hello" {
+				t.Errorf("content of %v = %q, want %q", tt.to, got, "hello")
+			}
+		})
+	}
+
+	// an existing target is kept with Overwrite: F
+	resp, _ := doWebDAV(t, "MOVE", server.URL+"/video/movies/c.txt", "", map[string]string{
+		"Destination": server.URL + "/video/movies/b.txt",
+		"Overwrite":   "F",
+	})
+	if resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("MOVE onto existing file status = %v, want %v", resp.StatusCode, http.StatusPreconditionFailed)
+	}
+}
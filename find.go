@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func findFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("find")
+	name := flags.String("name", "", "File name pattern")
+	ext := flags.String("ext", "", "File extension")
+	fileType := flags.String("type", "", "f for files, d for folders")
+	size := flags.String("size", "", "Size larger (+1G) or smaller (-100M) than")
+	mtime := flags.String("mtime", "", "Modified more (+30) or less (-7) than days ago")
+	owner := flags.String("user", "", "File owner")
+	long := flags.Bool("l", false, "Use long listing format")
+	remove := flags.Bool("delete", false, "Delete found files")
+	moveTo := flags.String("move", "", "Move found files to folder")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 || (*remove && *moveTo != "") {
+		flags.Usage()
+		return
+	}
+
+	opts := synoclient.SearchOptions{
+		Recursive:  true,
+		Pattern:    *name,
+		Extension:  *ext,
+		Owner:      *owner,
+		Additional: synoclient.DefaultFileAdditional,
+	}
+
+	switch *fileType {
+	case "":
+	case "f":
+		opts.FileType = "file"
+	case "d":
+		opts.FileType = "dir"
+	default:
+		flags.Usage()
+		return
+	}
+
+	if *size != "" {
+		bytes, err := parseSize(strings.TrimLeft(*size, "+-"))
+		if err != nil {
+			printError(err)
+			return
+		}
+		if strings.HasPrefix(*size, "-") {
+			opts.SizeTo = bytes
+		} else {
+			opts.SizeFrom = bytes
+		}
+	}
+
+	if *mtime != "" {
+		days, err := strconv.Atoi(strings.TrimLeft(*mtime, "+-"))
+		if err != nil {
+			printErrorf("Invalid mtime %v\n", *mtime)
+			return
+		}
+		since := time.Now().AddDate(0, 0, -days)
+		if strings.HasPrefix(*mtime, "-") {
+			opts.MtimeFrom = since
+		} else {
+			opts.MtimeTo = since
+		}
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	it, err := client.Search(positional, opts)
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer it.Close()
+
+	// results are streamed as tables, other formats need the whole list
+	var found []string
+	var files []synoclient.FileInfo
+	for it.Next() {
+		file := it.File()
+		found = append(found, file.Path)
+		switch {
+		case structuredOutput():
+			files = append(files, file)
+		case *long:
+			printLongFile(file, file.Path)
+		default:
+			fmt.Println(file.Path)
+		}
+	}
+	if err := it.Err(); err != nil {
+		printError(err)
+		return
+	}
+	if !*remove && *moveTo == "" {
+		if structuredOutput() {
+			printOutput(files, fileHeader, fileRows(files), nil)
+		}
+		return
+	}
+
+	// found files are reported by the results of deleting or moving them
+	action := "moved"
+	if *remove {
+		action = "deleted"
+	}
+	if len(found) == 0 {
+		outputFileResults(nil)
+		return
+	}
+
+	var task *synoclient.FileTask
+	if *remove {
+		task, err = client.Delete(found, true)
+	} else {
+		task, err = client.Move(found, *moveTo, synoclient.CopyMoveOptions{})
+	}
+	if err == nil {
+		_, err = task.Wait(context.Background())
+	}
+	if err != nil {
+		printError(err)
+	} else if *remove {
+		printMessage("%v file(s) deleted.\n", len(found))
+	} else {
+		printMessage("%v file(s) moved to %v.\n", len(found), *moveTo)
+	}
+	outputFileResults(pathResults(found, action, *moveTo, err))
+}
+
+// parseSize parses sizes like 100k, 1.5M or 1G in binary units
+func parseSize(size string) (int64, error) {
+	units := map[string]float64{"": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40}
+	number := strings.TrimRight(size, "kKmMgGtTbB")
+	unit := strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(size[len(number):], "B"), "b"))
+
+	multiplier, ok := units[unit]
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil {
+		return 0, fmt.Errorf("Invalid size %v", size)
+	}
+	return int64(value * multiplier), nil
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// gateway exposes Download Station and FileStation operations as REST
+// endpoints using a single DSM session
+type gateway struct {
+	client *synoclient.Client
+	token  string
+	// allowOrigins are origins of browser clients allowed by CORS, * allows any
+	allowOrigins []string
+}
+
+// apiTask is the JSON view of a download task
+type apiTask struct {
+	ID             string    `json:"id"`
+	Title          string    `json:"title"`
+	Type           string    `json:"type"`
+	Status         string    `json:"status"`
+	Size           int64     `json:"size"`
+	SizeDownloaded int64     `json:"size_downloaded"`
+	SizeUploaded   int64     `json:"size_uploaded"`
+	SpeedDownload  int64     `json:"speed_download"`
+	SpeedUpload    int64     `json:"speed_upload"`
+	Destination    string    `json:"destination"`
+	URI            string    `json:"uri"`
+	Username       string    `json:"username"`
+	CreateTime     time.Time `json:"create_time"`
+	CompletedTime  time.Time `json:"completed_time"`
+}
+
+// apiResult is the outcome of an operation on a single task or URI
+type apiResult struct {
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+	Code  int    `json:"code,omitempty"`
+}
+
+func serveAPI(client *synoclient.Client, args []string) {
+	flags := newFlagSet("serve")
+	listen := flags.String("listen", "127.0.0.1:8081", "Address to listen on")
+	token := flags.String("token", os.Getenv("SYNOGO_API_TOKEN"), "Token required as bearer token, generated if empty")
+	var allowOrigins stringList
+	flags.Var(&allowOrigins, "allow-origin", "Allow browser requests from this origin, * allows any (repeatable)")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	if *token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			printError(err)
+			return
+		}
+		*token = hex.EncodeToString(b)
+		log.Printf("Token: %v", *token)
+	}
+
+	// Login, the session is renewed when it expires
+	client.KeepSession = true
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	g := &gateway{client: client, token: *token, allowOrigins: allowOrigins}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tasks", g.authorized(g.tasks))
+	mux.HandleFunc("/api/tasks/", g.authorized(g.task))
+	mux.HandleFunc("/api/fs/move", g.authorized(g.move))
+	mux.HandleFunc("/api/fs/rename", g.authorized(g.rename))
+
+	log.Printf("Serving API on %v", *listen)
+	listenAndServe(*listen, mux)
+}
+
+// authorized requires the token as bearer token. It is not accepted as
+// query parameter, which would end up in access logs. CORS preflight
+// requests are answered before, browsers send them without credentials.
+func (g *gateway) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.cors(w, r) {
+			return
+		}
+		auth := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(auth, "Bearer ")
+		if token == auth || subtle.ConstantTimeCompare([]byte(token), []byte(g.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "Unauthorized"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// cors sets the Access-Control-Allow headers for allowed origins and
+// reports whether the request was a preflight request, which is answered
+func (g *gateway) cors(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !g.allowedOrigin(origin) {
+		return false
+	}
+	w.Header().Add("Vary", "Origin")
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return false
+	}
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.Header().Set("Access-Control-Max-Age", "600")
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+func (g *gateway) allowedOrigin(origin string) bool {
+	for _, allowed := range g.allowOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// tasks handles GET and POST /api/tasks
+func (g *gateway) tasks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tasks, err := g.client.ListDownloadStationTasks()
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		views := []apiTask{}
+		for _, task := range tasks {
+			views = append(views, newAPITask(task))
+		}
+		writeJSON(w, http.StatusOK, views)
+
+	case http.MethodPost:
+		var body struct {
+			URIs           []string `json:"uris"`
+			SkipDuplicates bool     `json:"skip_duplicates"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.URIs) == 0 {
+			writeJSON(w, http.StatusBadRequest, apiError{Error: "Expected {\"uris\": [...]}"})
+			return
+		}
+
+		index, err := newTaskIndex(g.client, body.SkipDuplicates)
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+		// uris are passed one per line, so lines are their indexes
+		failed := map[int]string{}
+		err = queueDownloadTasks(g.client, strings.NewReader(strings.Join(body.URIs, "\n")), index, func(line int, data *synoclient.TaskAddError) {
+			failed[line] = data.Err.Error()
+		})
+		if err != nil {
+			writeAPIError(w, err)
+			return
+		}
+
+		var results []apiResult
+		for i, uri := range body.URIs {
+			results = append(results, apiResult{ID: uri, Error: failed[i]})
+		}
+		status := http.StatusCreated
+		if len(failed) > 0 {
+			status = http.StatusMultiStatus
+		}
+		writeJSON(w, status, results)
+
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "Method not allowed"})
+	}
+}
+
+// task handles /api/tasks/<id> and /api/tasks/<id>/pause|resume
+func (g *gateway) task(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/tasks/"), "/")
+	id := parts[0]
+	action := ""
+	if len(parts) > 1 {
+		action = parts[1]
+	}
+
+	var resp string
+	var err error
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		task, err := g.client.GetDownloadStationTask(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, apiError{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, newAPITask(task))
+		return
+
+	case r.Method == http.MethodDelete && action == "":
+		resp, err = g.client.DeleteDownloadStationTasks(id)
+
+	case r.Method == http.MethodPatch && action == "":
+		var body struct {
+			Destination string `json:"destination"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Destination == "" {
+			writeJSON(w, http.StatusBadRequest, apiError{Error: "Expected {\"destination\": \"...\"}"})
+			return
+		}
+		resp, err = g.client.EditDownloadStationTasks(id, body.Destination)
+
+	case r.Method == http.MethodPost && action == "pause":
+		resp, err = g.client.PauseDownloadStationTasks(id)
+
+	case r.Method == http.MethodPost && action == "resume":
+		resp, err = g.client.ResumeDownloadStationTasks(id)
+
+	default:
+		writeJSON(w, http.StatusNotFound, apiError{Error: "Not found"})
+		return
+	}
+
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	result := apiTaskResult(g.client, resp, id)
+	if result.Error != "" {
+		writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// move handles POST /api/fs/move and waits for the move to finish
+func (g *gateway) move(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Paths       []string `json:"paths"`
+		Destination string   `json:"destination"`
+		Overwrite   bool     `json:"overwrite"`
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "Method not allowed"})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Paths) == 0 || body.Destination == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "Expected {\"paths\": [...], \"destination\": \"...\"}"})
+		return
+	}
+
+	opts := synoclient.CopyMoveOptions{}
+	if body.Overwrite {
+		opts.Conflict = synoclient.ConflictOverwrite
+	}
+	task, err := g.client.Move(body.Paths, body.Destination, opts)
+	if err == nil {
+		_, err = task.Wait(r.Context())
+	}
+	if errors.Is(err, context.Canceled) {
+		task.Stop()
+	}
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"destination": body.Destination})
+}
+
+// rename handles POST /api/fs/rename
+func (g *gateway) rename(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Path string `json:"path"`
+		Name string `json:"name"`
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "Method not allowed"})
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Path == "" || body.Name == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "Expected {\"path\": \"...\", \"name\": \"...\"}"})
+		return
+	}
+
+	renamed, err := g.client.RenameFile(body.Path, body.Name)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"path": renamed})
+}
+
+func newAPITask(task synoclient.DownloadStationTask) apiTask {
+	return apiTask{
+		ID:             task.ID,
+		Title:          task.Title,
+		Type:           task.Type,
+		Status:         task.Status,
+		Size:           task.Size,
+		SizeDownloaded: task.AdditinalTaskInfo.TaskTransfer.SizeDownloaded,
+		SizeUploaded:   task.AdditinalTaskInfo.TaskTransfer.SizeUploaded,
+		SpeedDownload:  task.AdditinalTaskInfo.TaskTransfer.SpeedDownload,
+		SpeedUpload:    task.AdditinalTaskInfo.TaskTransfer.SpeedUpload,
+		Destination:    task.AdditinalTaskInfo.TaskDetail.Destination,
+		URI:            task.AdditinalTaskInfo.TaskDetail.Uri,
+		Username:       task.Username,
+		CreateTime:     task.AdditinalTaskInfo.TaskDetail.CreateTime,
+		CompletedTime:  task.AdditinalTaskInfo.TaskDetail.CompletedTime,
+	}
+}
+
+// apiTaskResult returns the result for id of a delete, pause, resume or edit
+// response
+func apiTaskResult(client *synoclient.Client, resp string, id string) apiResult {
+	results, _ := client.GetData(resp).([]interface{})
+	for _, result := range results {
+		r, _ := result.(map[string]interface{})
+		if r["id"] != id {
+			continue
+		}
+		if code, _ := r["error"].(float64); code > 0 {
+			return apiResult{ID: id, Error: synoclient.DsSynoErrors[int(code)]}
+		}
+	}
+	return apiResult{ID: id}
+}
+
+// writeAPIError reports transport failures as bad gateway and errors of
+// the Synology API as unprocessable
+func writeAPIError(w http.ResponseWriter, err error) {
+	code := synoclient.ErrorCode(err)
+	status := http.StatusUnprocessableEntity
+	if code == 0 {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, apiError{Error: err.Error(), Code: code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
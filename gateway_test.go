@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGatewayCORS(t *testing.T) {
+	g := &gateway{token: "secret", allowOrigins: []string{"https://app.example.com"}}
+	handler := g.authorized(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		token      string
+		preflight  bool
+		wantStatus int
+		wantOrigin string
+	}{
+		{name: "preflight", method: http.MethodOptions, origin: "https://app.example.com", preflight: true,
+			wantStatus: http.StatusNoContent, wantOrigin: "https://app.example.com"},
+		{name: "preflight of other origin", method: http.MethodOptions, origin: "https://evil.example.com", preflight: true,
+			wantStatus: http.StatusUnauthorized},
+		{name: "request", method: http.MethodGet, origin: "https://app.example.com", token: "secret",
+			wantStatus: http.StatusOK, wantOrigin: "https://app.example.com"},
+		{name: "request without token", method: http.MethodGet, origin: "https://app.example.com",
+			wantStatus: http.StatusUnauthorized, wantOrigin: "https://app.example.com"},
+		{name: "request of other origin", method: http.MethodGet, origin: "https://evil.example.com", token: "secret",
+			wantStatus: http.StatusOK},
+		{name: "options without preflight", method: http.MethodOptions, origin: "https://app.example.com",
+			wantStatus: http.StatusUnauthorized, wantOrigin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/tasks", nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if tt.wantStatus == http.StatusNoContent && w.Header().Get("Access-Control-Allow-Headers") == "" {
+				t.Error("preflight response without Access-Control-Allow-Headers")
+			}
+		})
+	}
+}
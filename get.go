@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func getFile(client *synoclient.Client, args []string) {
+	flags := newFlagSet("get")
+	retries := flags.Int("retries", 3, "Number of retries resuming interrupted downloads")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 || *retries < 0 {
+		flags.Usage()
+		return
+	}
+	remote, local := positional[0], positional[1]
+
+	// downloading into a directory keeps the remote name
+	localDir := ""
+	if info, err := os.Stat(local); err == nil && info.IsDir() {
+		localDir = local
+		local = filepath.Join(localDir, path.Base(remote))
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	// the file is written to .part until complete, so that an
+	// interrupted download can be resumed
+	for attempt := 0; ; attempt++ {
+		local, err = downloadFile(client, remote, local, localDir, true)
+		if err == nil {
+			break
+		}
+		if attempt >= *retries {
+			printError(err)
+			break
+		}
+		printWarning(err)
+		time.Sleep(time.Duration(attempt+1) * 2 * time.Second)
+		printMessage("Retrying...\n")
+	}
+
+	if err == nil {
+		printMessage("Downloaded %v.\n", local)
+	}
+	outputFileResults([]fileResult{newFileResult(remote, "downloaded", local, err)})
+}
+
+// downloadFile downloads remote into local resuming a partial download
+// and returns the name of the downloaded file
+func downloadFile(client *synoclient.Client, remote string, local string, localDir string, showProgress bool) (string, error) {
+	info, err := client.Stat(remote)
+	if err != nil {
+		return local, err
+	}
+
+	// a partial download is only resumed if the remote file is unchanged,
+	// folders are zipped on the fly and always start over
+	var offset int64
+	partial := partialDownload{Size: info.Size, Mtime: info.Time.Mtime.Unix()}
+	if !info.IsDir {
+		if part, err := os.Stat(local + ".part"); err == nil && readPartialDownload(local) == partial {
+			offset = part.Size()
+		}
+	}
+
+	download, err := client.Download(remote, offset)
+	if errors.Is(err, synoclient.ErrRangeNotSatisfiable) {
+		// the partial file is complete or longer than the remote file
+		if info.Size == offset {
+			os.Remove(local + ".part.json")
+			return local, os.Rename(local+".part", local)
+		}
+		download, err = client.Download(remote, 0)
+	}
+	if err != nil {
+		return local, err
+	}
+	defer download.Close()
+
+	// folders are downloaded as zip archive
+	if localDir != "" && strings.HasSuffix(download.Name, ".zip") && !strings.HasSuffix(local, ".zip") {
+		local += ".zip"
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if download.Offset == 0 {
+		// the server did not resume, start over
+		flags = os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	}
+	if !info.IsDir {
+		if err := writePartialDownload(local, partial); err != nil {
+			return local, err
+		}
+	}
+	file, err := os.OpenFile(local+".part", flags, 0644)
+	if err != nil {
+		return local, err
+	}
+
+	var w io.Writer = file
+	if showProgress {
+		w = &progressWriter{w: file, written: download.Offset, size: download.Size}
+		defer fmt.Fprintln(messageOutput())
+	}
+	_, err = io.Copy(w, download)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return local, err
+	}
+
+	os.Remove(local + ".part.json")
+	return local, os.Rename(local+".part", local)
+}
+
+// partialDownload identifies the remote file a .part file belongs to, it
+// is stored next to it in .part.json
+type partialDownload struct {
+	Size  int64 `json:"size"`
+	Mtime int64 `json:"mtime"`
+}
+
+func readPartialDownload(local string) partialDownload {
+	var partial partialDownload
+	if data, err := ioutil.ReadFile(local + ".part.json"); err == nil {
+		json.Unmarshal(data, &partial)
+	}
+	return partial
+}
+
+func writePartialDownload(local string, partial partialDownload) error {
+	data, err := json.Marshal(partial)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(local+".part.json", data, 0644)
+}
+
+// progressWriter prints the number of bytes written
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	size    int64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.size >= 0 {
+		fmt.Fprintf(messageOutput(), "\r%v of %v", ByteCountSI(p.written), ByteCountSI(p.size))
+	} else {
+		fmt.Fprintf(messageOutput(), "\r%v", ByteCountSI(p.written))
+	}
+	return n, err
+}
@@ -1,8 +1,10 @@
 module github.com/macpoint/synogo
 
-go 1.13
+go 1.16
 
 require (
 	github.com/olekukonko/tablewriter v0.0.4
 	github.com/pkg/errors v0.9.1
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
+	gopkg.in/yaml.v2 v2.4.0
 )
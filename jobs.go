@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func listJobs(client *synoclient.Client, args []string) {
+	flags := newFlagSet("jobs")
+	var apis stringList
+	flags.Var(&apis, "api", "Only list tasks of this API, e.g. CopyMove (repeatable)")
+	clear := flags.Bool("clear", false, "Clear finished tasks")
+	wait := flags.String("wait", "", "Wait for the task with this id to finish")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	if *clear {
+		tasks, _, err := client.ListBackgroundTasks(synoclient.BackgroundTaskOptions{})
+		if err != nil {
+			printError(err)
+			return
+		}
+		err = client.ClearFinishedBackgroundTasks(nil)
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("Finished tasks cleared.\n")
+		}
+		var results []jobResult
+		for _, task := range tasks {
+			if task.Finished {
+				results = append(results, newJobResult(task, "cleared", err))
+			}
+		}
+		outputJobResults(results)
+		return
+	}
+
+	var filter []string
+	for _, api := range apis {
+		if !strings.HasPrefix(api, "SYNO.") {
+			api = "SYNO.FileStation." + api
+		}
+		filter = append(filter, api)
+	}
+	tasks, _, err := client.ListBackgroundTasks(synoclient.BackgroundTaskOptions{
+		SortBy:        "crtime",
+		SortDirection: "desc",
+		APIs:          filter,
+	})
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	if *wait == "" {
+		printOutput(tasks, backgroundTaskHeader, backgroundTaskRows(tasks), nil)
+		return
+	}
+
+	for _, task := range tasks {
+		if task.TaskID != *wait {
+			continue
+		}
+		var err error
+		if !task.Finished {
+			err = waitFileTask(task.Task(client))
+		}
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("Task finished.\n")
+		}
+		outputJobResults([]jobResult{newJobResult(task, "finished", err)})
+		return
+	}
+	printErrorf("Could not find task %v\n", *wait)
+	outputJobResults([]jobResult{{ID: *wait, Action: "finished", Error: "not found"}})
+}
+
+// jobResult is the outcome of an operation on a background task
+type jobResult struct {
+	ID     string `json:"id"`
+	Type   string `json:"type,omitempty"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+func newJobResult(task synoclient.BackgroundTask, action string, err error) jobResult {
+	result := jobResult{
+		ID:     task.TaskID,
+		Type:   strings.TrimPrefix(task.API, "SYNO.FileStation.") + " " + task.Method,
+		Action: action,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// outputJobResults writes background task results in structured formats,
+// tables are already printed as messages
+func outputJobResults(results []jobResult) {
+	var rows [][]string
+	for _, result := range results {
+		rows = append(rows, []string{result.ID, result.Type, result.Action, result.Error})
+	}
+	printOutput(results, []string{"Id", "Type", "Action", "Error"}, rows, func() {})
+}
+
+var backgroundTaskHeader = []string{"Id", "Type", "Path", "Created", "Progress", "Finished"}
+
+func backgroundTaskRows(tasks []synoclient.BackgroundTask) [][]string {
+	var rows [][]string
+	for _, task := range tasks {
+		created := ""
+		if !task.Created.IsZero() {
+			created = task.Created.Format("2006-01-02 15:04")
+		}
+		path := task.ProcessingPath
+		if path == "" {
+			path = task.Path
+		}
+		rows = append(rows, []string{
+			task.TaskID,
+			strings.TrimPrefix(task.API, "SYNO.FileStation.") + " " + task.Method,
+			path,
+			created,
+			fmt.Sprintf("%.0f%%", task.Progress*100),
+			fmt.Sprintf("%v", task.Finished),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+	"github.com/olekukonko/tablewriter"
+)
+
+func listFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("ls")
+	long := flags.Bool("l", false, "Use long listing format")
+	pattern := flags.String("pattern", "", "List only files matching pattern")
+	sortBy := flags.String("sort", "name", "Sort by name, size, user, group, mtime, atime, ctime, crtime, posix or type")
+	reverse := flags.Bool("reverse", false, "Reverse sort order")
+
+	positional := parseArgs(flags, args)
+	if len(positional) > 1 {
+		flags.Usage()
+		return
+	}
+
+	// no path lists shared folders
+	var folder string
+	if len(positional) == 1 {
+		folder = positional[0]
+	}
+
+	opts := synoclient.ListOptions{
+		SortBy:        *sortBy,
+		SortDirection: "asc",
+		Pattern:       *pattern,
+		Additional:    synoclient.DefaultFileAdditional,
+	}
+	if *reverse {
+		opts.SortDirection = "desc"
+	}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	var files []synoclient.FileInfo
+	it := client.NewFileIterator(folder, opts)
+	for it.Next() {
+		files = append(files, it.File())
+	}
+	if err := it.Err(); err != nil {
+		printError(err)
+		return
+	}
+
+	printOutput(files, fileHeader, fileRows(files), func() {
+		if *long {
+			for _, file := range files {
+				printLongFile(file, file.Name)
+			}
+			return
+		}
+		formatFiles(files)
+	})
+}
+
+// fileHeader and fileRows are the csv columns of files
+var fileHeader = []string{"Path", "Name", "Type", "Size", "Owner", "Group", "Perm", "Modified"}
+
+func fileRows(files []synoclient.FileInfo) [][]string {
+	var rows [][]string
+	for _, file := range files {
+		fileType := "file"
+		if file.IsDir {
+			fileType = "dir"
+		}
+		rows = append(rows, []string{
+			file.Path,
+			file.Name,
+			fileType,
+			strconv.FormatInt(file.Size, 10),
+			file.Owner.User,
+			file.Owner.Group,
+			strconv.Itoa(file.Perm.Posix),
+			file.Time.Mtime.Format(time.RFC3339),
+		})
+	}
+	return rows
+}
+
+// printLongFile prints file in long listing format
+func printLongFile(file synoclient.FileInfo, name string) {
+	fmt.Printf("%v %-8v %-8v %10v %v %v\n",
+		formatPerm(file),
+		file.Owner.User,
+		file.Owner.Group,
+		file.Size,
+		file.Time.Mtime.Format("2006-01-02 15:04"),
+		name)
+}
+
+func formatFiles(files []synoclient.FileInfo) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "Type", "Size", "Owner", "Modified"})
+	for _, file := range files {
+		fileType, size := "file", ByteCountSI(file.Size)
+		if file.IsDir {
+			fileType, size = "dir", ""
+		}
+		table.Append([]string{
+			file.Name,
+			fileType,
+			size,
+			file.Owner.User,
+			file.Time.Mtime.Format("2006-01-02 15:04"),
+		})
+	}
+	table.Render()
+}
+
+// formatPerm formats posix permissions, e.g. 755 as drwxr-xr-x
+func formatPerm(file synoclient.FileInfo) string {
+	var perm strings.Builder
+	if file.IsDir {
+		perm.WriteString("d")
+	} else {
+		perm.WriteString("-")
+	}
+
+	digits := fmt.Sprintf("%03d", file.Perm.Posix)
+	for _, digit := range digits[len(digits)-3:] {
+		bits := digit - '0'
+		for i, flag := range "rwx" {
+			if bits&(4>>uint(i)) != 0 {
+				perm.WriteRune(flag)
+			} else {
+				perm.WriteString("-")
+			}
+		}
+	}
+	return perm.String()
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// organizeRule maps finished tasks to a target folder and name template
+type organizeRule struct {
+	Name string `json:"name"`
+	// Title is a regular expression, its named groups are available in the template
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Destination string `json:"destination"`
+	MinSize     int64  `json:"min_size"`
+	MaxSize     int64  `json:"max_size"`
+	// Target folder starting with a shared folder, e.g. /video/tv
+	Target string `json:"target"`
+	// Template of the path within the target folder, defaults to {{.Title}}
+	Template string `json:"template"`
+	// Clear deletes the task once its file is moved
+	Clear bool `json:"clear"`
+
+	title       *regexp.Regexp
+	destination *regexp.Regexp
+	template    *template.Template
+}
+
+func loadOrganizeRules(file string) ([]*organizeRule, error) {
+	rulesFile, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rulesFile.Close()
+
+	var rules []*organizeRule
+	if err := json.NewDecoder(rulesFile).Decode(&rules); err != nil {
+		return nil, fmt.Errorf("Could not parse JSON rules: %v", err)
+	}
+
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%v", i+1)
+		}
+		if rule.Target == "" {
+			return nil, fmt.Errorf("Rule %v: missing target", rule.Name)
+		}
+		if rule.Template == "" {
+			rule.Template = "{{.Title}}"
+		}
+		if rule.title, err = regexp.Compile(rule.Title); err != nil {
+			return nil, fmt.Errorf("Rule %v: %v", rule.Name, err)
+		}
+		if rule.destination, err = regexp.Compile(rule.Destination); err != nil {
+			return nil, fmt.Errorf("Rule %v: %v", rule.Name, err)
+		}
+		rule.template, err = template.New(rule.Name).Option("missingkey=error").Parse(rule.Template)
+		if err != nil {
+			return nil, fmt.Errorf("Rule %v: %v", rule.Name, err)
+		}
+	}
+	return rules, nil
+}
+
+// match returns the template fields if the task matches the rule
+func (rule *organizeRule) match(task synoclient.DownloadStationTask) (map[string]string, bool) {
+	if rule.Type != "" && rule.Type != task.Type {
+		return nil, false
+	}
+	if rule.MinSize > 0 && task.Size < rule.MinSize {
+		return nil, false
+	}
+	if rule.MaxSize > 0 && task.Size > rule.MaxSize {
+		return nil, false
+	}
+	if !rule.destination.MatchString(task.AdditinalTaskInfo.TaskDetail.Destination) {
+		return nil, false
+	}
+	groups := rule.title.FindStringSubmatch(task.Title)
+	if groups == nil {
+		return nil, false
+	}
+
+	ext := path.Ext(task.Title)
+	fields := map[string]string{
+		"ID":          task.ID,
+		"Title":       task.Title,
+		"Name":        strings.TrimSuffix(task.Title, ext),
+		"Ext":         ext,
+		"Type":        task.Type,
+		"Destination": task.AdditinalTaskInfo.TaskDetail.Destination,
+	}
+	for i, name := range rule.title.SubexpNames() {
+		if name != "" {
+			fields[name] = groups[i]
+		}
+	}
+	return fields, true
+}
+
+// target returns the full destination path of the task file. Fields come
+// from task titles, paths leaving the target folder, e.g. by ../, fail.
+func (rule *organizeRule) target(fields map[string]string) (string, error) {
+	var name strings.Builder
+	if err := rule.template.Execute(&name, fields); err != nil {
+		return "", err
+	}
+	folder := path.Clean(rule.Target)
+	target := path.Join(folder, name.String())
+	if !strings.HasPrefix(target, strings.TrimSuffix(folder, "/")+"/") {
+		return "", fmt.Errorf("%v is not within the target folder %v", target, folder)
+	}
+	return target, nil
+}
+
+func organizeDownloadTasks(client *synoclient.Client, args []string) {
+	flags := newFlagSet("organize")
+	rulesFile := flags.String("rules", filepath.Join(os.Getenv("HOME"), ".synogo.rules.json"), "JSON rule file")
+	dryRun := flags.Bool("dry-run", false, "Only print what would be done")
+
+	if len(parseArgs(flags, args)) != 0 {
+		flags.Usage()
+		return
+	}
+
+	rules, err := loadOrganizeRules(*rulesFile)
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	// Login
+	_, err = client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	tasks, err := client.ListDownloadStationTasks()
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	var results []taskResult
+	for _, task := range tasks {
+		if task.Status != "finished" {
+			continue
+		}
+
+		rule, fields := matchOrganizeRule(rules, task)
+		if rule == nil {
+			continue
+		}
+
+		destination, err := rule.target(fields)
+		if err != nil {
+			printErrorf("Task %v not organized: %v\n", task.ID, err)
+			results = append(results, taskResult{ID: task.ID, Action: "organize", Error: err.Error()})
+			continue
+		}
+
+		printMessage("Task %v (rule %v): %v -> %v\n", task.ID, rule.Name, task.Title, destination)
+		if *dryRun {
+			results = append(results, taskResult{ID: task.ID, Action: "move to " + destination})
+			continue
+		}
+
+		result := taskResult{ID: task.ID, Action: "moved to " + destination}
+		if err := relocateTask(client, task, destination); err != nil {
+			printErrorf("Task %v not organized: %v\n", task.ID, err)
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		results = append(results, result)
+
+		if rule.Clear {
+			resp, err := client.DeleteDownloadStationTasks(task.ID)
+			if err != nil {
+				printError(err)
+				results = append(results, taskResult{ID: task.ID, Action: "deleted", Error: err.Error()})
+				continue
+			}
+			results = append(results, printTaskResults(client, resp, "delete", "deleted")...)
+		}
+	}
+	outputTaskResults(results)
+}
+
+// matchOrganizeRule returns the first rule matching the task
+func matchOrganizeRule(rules []*organizeRule, task synoclient.DownloadStationTask) (*organizeRule, map[string]string) {
+	for _, rule := range rules {
+		if fields, ok := rule.match(task); ok {
+			return rule, fields
+		}
+	}
+	return nil, nil
+}
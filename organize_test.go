@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"text/template"
+)
+
+func TestOrganizeRuleTarget(t *testing.T) {
+	rule := &organizeRule{
+		Target:   "/video/tv/",
+		template: template.Must(template.New("tv").Parse("{{.show}}/Season {{.season}}/{{.Title}}")),
+	}
+
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   string
+	}{
+		{name: "within target", fields: map[string]string{"show": "Show", "season": "1", "Title": "Show.S01E01.mkv"},
+			want: "/video/tv/Show/Season 1/Show.S01E01.mkv"},
+		{name: "dot dot kept inside", fields: map[string]string{"show": "Show/..", "season": "1", "Title": "a.mkv"},
+			want: "/video/tv/Season 1/a.mkv"},
+		{name: "escaping", fields: map[string]string{"show": "../../..", "season": "1/../..", "Title": "etc/passwd"}},
+		{name: "sibling folder", fields: map[string]string{"show": "../tvshows", "season": "1", "Title": "a.mkv"}},
+		{name: "target folder itself", fields: map[string]string{"show": ".", "season": "1/..", "Title": "/Season 1/.."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rule.target(tt.fields)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("target(%v) = %v, want error", tt.fields, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("target(%v) = %v, %v, want %v", tt.fields, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+// global --output and --template flags, valid for every command
+var (
+	outputFormat   = "table"
+	outputTemplate = ""
+)
+
+var outputFormats = []string{"table", "json", "yaml", "csv", "tsv"}
+
+// parseOutputFlags removes the global output flags from args, they may be
+// given before or after the command
+func parseOutputFlags(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.TrimLeft(args[i], "-"), "", false
+		if !strings.HasPrefix(args[i], "-") {
+			rest = append(rest, args[i])
+			continue
+		}
+		if eq := strings.Index(name, "="); eq >= 0 {
+			name, value, hasValue = name[:eq], name[eq+1:], true
+		}
+		if name != "output" && name != "template" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("Flag --%v needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if name == "template" {
+			outputTemplate = value
+			continue
+		}
+		valid := false
+		for _, format := range outputFormats {
+			valid = valid || value == format
+		}
+		if !valid {
+			return nil, fmt.Errorf("Invalid output format %v, use %v", value, strings.Join(outputFormats, ", "))
+		}
+		outputFormat = value
+	}
+	return rest, nil
+}
+
+// structuredOutput reports whether stdout is reserved for machine readable
+// output, messages and progress go to stderr then
+func structuredOutput() bool {
+	return outputFormat != "table" || outputTemplate != ""
+}
+
+// messageOutput is where progress and status messages are written
+func messageOutput() io.Writer {
+	if structuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printMessage prints a status message which is not part of the output
+func printMessage(format string, a ...interface{}) {
+	fmt.Fprintf(messageOutput(), format, a...)
+}
+
+// printError prints an error to stderr and makes synogo exit with status 1
+func printError(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	setExitStatus(1)
+}
+
+// printWarning prints an error to stderr which does not fail the command,
+// e.g. a failed attempt which is retried
+func printWarning(err error) {
+	fmt.Fprintln(os.Stderr, err)
+}
+
+// printErrorf is printError with a formatted message
+func printErrorf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, a...)
+	setExitStatus(1)
+}
+
+// printOutput writes v in the selected format. Tables and csv are built
+// from header and rows, human replaces the table if not nil. Templates are
+// executed for each element of slices.
+func printOutput(v interface{}, header []string, rows [][]string, human func()) {
+	// empty lists are [] rather than null
+	if value := reflect.ValueOf(v); value.Kind() == reflect.Slice && value.IsNil() {
+		v = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+
+	var err error
+	switch {
+	case outputTemplate != "":
+		err = printTemplate(v)
+	case outputFormat == "json":
+		err = printJSON(v)
+	case outputFormat == "yaml":
+		err = printYAML(v)
+	case outputFormat == "csv" || outputFormat == "tsv":
+		w := csv.NewWriter(os.Stdout)
+		if outputFormat == "tsv" {
+			w.Comma = '\t'
+		}
+		w.Write(header)
+		w.WriteAll(rows)
+		err = w.Error()
+	case human != nil:
+		human()
+	default:
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(header)
+		table.AppendBulk(rows)
+		table.Render()
+	}
+	if err != nil {
+		printError(err)
+	}
+}
+
+func printJSON(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
+
+// printYAML converts v through JSON so both formats share the field names
+func printYAML(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return err
+	}
+	b, err = yaml.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+func printTemplate(v interface{}) error {
+	tmpl, err := template.New("output").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"bytes": ByteCountSI,
+	}).Parse(outputTemplate)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice {
+		value = reflect.ValueOf([]interface{}{v})
+	}
+	for i := 0; i < value.Len(); i++ {
+		if err := tmpl.Execute(os.Stdout, value.Index(i).Interface()); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// uploadJob is a local file to be uploaded into a remote folder
+type uploadJob struct {
+	local     string
+	remoteDir string
+}
+
+func putFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("put")
+	recursive := flags.Bool("r", false, "Upload directories recursively")
+	jobs := flags.Int("j", 4, "Number of parallel uploads")
+	overwrite := flags.Bool("overwrite", false, "Overwrite existing files")
+	skip := flags.Bool("skip", false, "Skip existing files")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 || *jobs < 1 || (*overwrite && *skip) {
+		flags.Usage()
+		return
+	}
+	local, remote := positional[0], positional[1]
+
+	opts := synoclient.UploadOptions{CreateParents: true}
+	if *overwrite {
+		opts.Conflict = synoclient.ConflictOverwrite
+	}
+	if *skip {
+		opts.Conflict = synoclient.ConflictSkip
+	}
+
+	info, err := os.Stat(local)
+	if err != nil {
+		printError(err)
+		return
+	}
+	if info.IsDir() && !*recursive {
+		printErrorf("%v is a directory, use -r to upload it.\n", local)
+		return
+	}
+
+	// Login
+	_, err = client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	if !info.IsDir() {
+		err := uploadFile(client, uploadJob{local: local, remoteDir: remote}, opts, true)
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("File uploaded.\n")
+		}
+		outputFileResults([]fileResult{newFileResult(local, "uploaded", remote, err)})
+		return
+	}
+
+	uploadDirectory(client, local, path.Join(remote, filepath.Base(local)), opts, *jobs)
+}
+
+// uploadDirectory uploads the local directory tree into remoteRoot using
+// a pool of workers
+func uploadDirectory(client *synoclient.Client, local string, remoteRoot string, opts synoclient.UploadOptions, noOfWorkers int) {
+	var processWg sync.WaitGroup
+	var errorWg sync.WaitGroup
+
+	processWg.Add(noOfWorkers)
+	errorWg.Add(1)
+	uploadQueue := make(chan uploadJob, noOfWorkers)
+	errorQueue := make(chan error)
+
+	var resultsMu sync.Mutex
+	var results []fileResult
+
+	// create workers
+	for gr := 1; gr <= noOfWorkers; gr++ {
+		go func() {
+			defer processWg.Done()
+			for job := range uploadQueue {
+				err := uploadFile(client, job, opts, false)
+				resultsMu.Lock()
+				results = append(results, newFileResult(job.local, "uploaded", job.remoteDir, err))
+				resultsMu.Unlock()
+				if err != nil {
+					errorQueue <- fmt.Errorf("%v not uploaded: %v", job.local, err)
+					continue
+				}
+				printMessage("Uploaded %v\n", job.local)
+			}
+		}()
+	}
+
+	// read the error queue
+	var failed int
+	go func() {
+		defer errorWg.Done()
+		for err := range errorQueue {
+			failed++
+			printError(err)
+		}
+	}()
+
+	// fill the queue with files of the tree
+	var total int
+	err := filepath.Walk(local, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(local, filepath.Dir(file))
+		if err != nil {
+			return err
+		}
+		total++
+		uploadQueue <- uploadJob{local: file, remoteDir: path.Join(remoteRoot, filepath.ToSlash(rel))}
+		return nil
+	})
+
+	close(uploadQueue)
+	processWg.Wait()
+
+	close(errorQueue)
+	errorWg.Wait()
+
+	if err != nil {
+		printError(err)
+	}
+	printMessage("%v file(s) uploaded, %v failed.\n", total-failed, failed)
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Path < results[j].Path })
+	outputFileResults(results)
+}
+
+// uploadFile uploads a single file keeping its modification time
+func uploadFile(client *synoclient.Client, job uploadJob, opts synoclient.UploadOptions, showProgress bool) error {
+	file, err := os.Open(job.local)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	opts.Mtime = info.ModTime()
+	opts.Crtime = info.ModTime()
+	if showProgress {
+		opts.Progress = func(uploaded int64) {
+			fmt.Fprintf(messageOutput(), "\r%v of %v", ByteCountSI(uploaded), ByteCountSI(info.Size()))
+		}
+		defer fmt.Fprintln(messageOutput())
+	}
+
+	return client.Upload(job.remoteDir, filepath.Base(job.local), file, info.Size(), opts)
+}
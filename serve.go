@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/macpoint/synogo/filestation"
+	"github.com/macpoint/synogo/synoclient"
+	"golang.org/x/net/webdav"
+)
+
+func serve(client *synoclient.Client, args []string) {
+	if len(args) == 0 {
+		newFlagSet("serve").Usage()
+		return
+	}
+
+	switch args[0] {
+	case "webdav":
+		serveWebDAV(client, args[1:])
+	case "api":
+		serveAPI(client, args[1:])
+	default:
+		newFlagSet("serve").Usage()
+	}
+}
+
+func serveWebDAV(client *synoclient.Client, args []string) {
+	flags := newFlagSet("serve")
+	listen := flags.String("listen", "127.0.0.1:8080", "Address to listen on, other than loopback addresses need --auth")
+	root := flags.String("root", "/", "Folder exposed as WebDAV root, / exposes all shares")
+	auth := flags.String("auth", "", "Require basic authentication with user:password")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+
+	// the share is writable with the rights of the DSM account
+	if *auth == "" && !isLoopback(*listen) {
+		printErrorf("Listening on %v requires --auth, only loopback addresses are served without authentication.\n", *listen)
+		return
+	}
+
+	// Login, the session is renewed when it expires
+	client.KeepSession = true
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	handler := &webdav.Handler{
+		FileSystem: filestation.NewWebDAV(client, *root),
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil {
+				log.Printf("%v %v: %v", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+
+	log.Printf("Serving %v over WebDAV on %v", *root, *listen)
+	listenAndServe(*listen, basicAuth(*auth, handler))
+}
+
+// listenAndServe serves handler until SIGINT or SIGTERM
+func listenAndServe(addr string, handler http.Handler) {
+	server := &http.Server{Addr: addr, Handler: handler}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		server.Shutdown(context.Background())
+	}()
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		log.Println(err)
+		setExitStatus(1)
+		return
+	}
+	log.Println("Stopped.")
+}
+
+// isLoopback reports whether addr only accepts local connections, an empty
+// host listens on all interfaces
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// basicAuth requires the user:password credentials, empty credentials
+// allow everybody
+func basicAuth(credentials string, handler http.Handler) http.Handler {
+	if credentials == "" {
+		return handler
+	}
+	user := strings.SplitN(credentials, ":", 2)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || len(user) != 2 ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(user[0])) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(user[1])) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="synogo"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
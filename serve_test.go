@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1:8080", want: true},
+		{addr: "[::1]:8080", want: true},
+		{addr: "localhost:8080", want: true},
+		{addr: ":8080", want: false},
+		{addr: "0.0.0.0:8080", want: false},
+		{addr: "192.168.1.10:8080", want: false},
+		{addr: "nas.local:8080", want: false},
+		{addr: "127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		if got := isLoopback(tt.addr); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
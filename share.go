@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func shareFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("share")
+	expires := flags.String("expires", "", "Expire after days (7d), duration (12h) or on date (2006-01-02)")
+	available := flags.String("available", "", "Available from date (2006-01-02) or after days (1d)")
+	password := flags.String("password", "", "Protect links with password")
+	list := flags.Bool("list", false, "List sharing links")
+	remove := flags.Bool("delete", false, "Delete sharing links by id")
+	clear := flags.Bool("clear", false, "Delete invalid sharing links")
+
+	positional := parseArgs(flags, args)
+	if len(positional) == 0 && !*list && !*clear {
+		flags.Usage()
+		return
+	}
+
+	var opts synoclient.SharingOptions
+	opts.Password = *password
+	var err error
+	if opts.DateExpired, err = parseDate(*expires); err != nil {
+		printError(err)
+		return
+	}
+	if opts.DateAvailable, err = parseDate(*available); err != nil {
+		printError(err)
+		return
+	}
+
+	// Login
+	_, err = client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	switch {
+	case *list:
+		links, _, err := client.ListSharingLinks(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		printOutput(links, sharingLinkHeader, sharingLinkRows(links), nil)
+
+	case *clear:
+		// the links are listed before and after to tell which were deleted
+		before, _, err := client.ListSharingLinks(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		if err := client.ClearInvalidSharingLinks(); err != nil {
+			printError(err)
+			return
+		}
+		after, _, err := client.ListSharingLinks(0, 0)
+		if err != nil {
+			printError(err)
+			return
+		}
+		remaining := make(map[string]bool)
+		for _, link := range after {
+			remaining[link.ID] = true
+		}
+		var results []sharingLinkResult
+		for _, link := range before {
+			if !remaining[link.ID] {
+				results = append(results, sharingLinkResult{ID: link.ID, Path: link.Path, Action: "deleted"})
+			}
+		}
+		printMessage("Invalid sharing links deleted.\n")
+		outputSharingLinkResults(results)
+
+	case *remove:
+		err := client.DeleteSharingLinks(positional)
+		var results []sharingLinkResult
+		for _, id := range positional {
+			result := sharingLinkResult{ID: id, Action: "deleted"}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+		if err != nil {
+			printError(err)
+		} else {
+			printMessage("Sharing links deleted.\n")
+		}
+		outputSharingLinkResults(results)
+
+	default:
+		links, err := client.CreateSharingLinks(positional, opts)
+		if err != nil {
+			printError(err)
+			return
+		}
+		printOutput(links, sharingLinkHeader, sharingLinkRows(links), func() {
+			for _, link := range links {
+				fmt.Printf("%v %v\n", link.Path, link.URL)
+			}
+		})
+	}
+}
+
+// sharingLinkResult is the outcome of an operation on a sharing link
+type sharingLinkResult struct {
+	ID     string `json:"id"`
+	Path   string `json:"path,omitempty"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// outputSharingLinkResults writes sharing link results in structured
+// formats, tables are already printed as messages
+func outputSharingLinkResults(results []sharingLinkResult) {
+	var rows [][]string
+	for _, result := range results {
+		rows = append(rows, []string{result.ID, result.Path, result.Action, result.Error})
+	}
+	printOutput(results, []string{"Id", "Path", "Action", "Error"}, rows, func() {})
+}
+
+var sharingLinkHeader = []string{"Id", "Path", "Url", "Password", "Expires", "Status"}
+
+func sharingLinkRows(links []synoclient.SharingLink) [][]string {
+	var rows [][]string
+	for _, link := range links {
+		expires := ""
+		if !link.DateExpired.IsZero() {
+			expires = link.DateExpired.Format("2006-01-02")
+		}
+		rows = append(rows, []string{
+			link.ID,
+			link.Path,
+			link.URL,
+			strconv.FormatBool(link.HasPassword),
+			expires,
+			link.Status,
+		})
+	}
+	return rows
+}
+
+// parseDate parses a date (2006-01-02), days from now (7d) or a duration
+// from now (12h). Sharing links are limited by whole days, so durations
+// are rounded up to days. Empty date is zero time.
+func parseDate(date string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", date, time.Local); err == nil {
+		return t, nil
+	}
+	if strings.HasSuffix(date, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(date, "d")); err == nil {
+			return time.Now().AddDate(0, 0, days), nil
+		}
+	}
+	if d, err := time.ParseDuration(date); err == nil && d > 0 {
+		days := int((d + 24*time.Hour - 1) / (24 * time.Hour))
+		if d%(24*time.Hour) != 0 {
+			printMessage("Sharing links are limited by whole days, %v is rounded up to %vd.\n", date, days)
+		}
+		return time.Now().AddDate(0, 0, days), nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid date %v", date)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/macpoint/synogo/filestation"
+	"github.com/macpoint/synogo/synoclient"
+)
+
+// syncOptions control how two trees are compared
+type syncOptions struct {
+	include  []string
+	exclude  []string
+	checksum bool
+	delete   bool
+}
+
+// syncPlan lists relative paths to be copied to and deleted from the destination
+type syncPlan struct {
+	copy   []string
+	delete []string
+}
+
+func syncFiles(client *synoclient.Client, args []string) {
+	flags := newFlagSet("sync")
+	reverse := flags.Bool("reverse", false, "Sync from the NAS to the local directory")
+	deleteExtraneous := flags.Bool("delete", false, "Delete files missing in the source")
+	dryRun := flags.Bool("dry-run", false, "Only print what would be done")
+	checksum := flags.Bool("checksum", false, "Compare files of equal size by MD5 instead of modification time")
+	jobs := flags.Int("j", 4, "Number of parallel transfers")
+	var include, exclude stringList
+	flags.Var(&include, "include", "Sync only files matching pattern (repeatable)")
+	flags.Var(&exclude, "exclude", "Skip files matching pattern (repeatable)")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 || *jobs < 1 {
+		flags.Usage()
+		return
+	}
+	localDir, remoteDir := positional[0], path.Clean(positional[1])
+	opts := syncOptions{include: include, exclude: exclude, checksum: *checksum, delete: *deleteExtraneous}
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	local, err := scanTree(os.DirFS(localDir), opts)
+	if err != nil && !(*reverse && errors.Is(err, fs.ErrNotExist)) {
+		printError(err)
+		return
+	}
+	remote, err := scanTree(filestation.New(client, remoteDir), opts)
+	if err != nil && !(!*reverse && errors.Is(err, fs.ErrNotExist)) {
+		printError(err)
+		return
+	}
+
+	var plan syncPlan
+	if *reverse {
+		plan, err = planSync(remote, local, opts, func(rel string) (bool, error) {
+			return sameChecksum(client, path.Join(remoteDir, rel), filepath.Join(localDir, filepath.FromSlash(rel)))
+		})
+	} else {
+		plan, err = planSync(local, remote, opts, func(rel string) (bool, error) {
+			return sameChecksum(client, path.Join(remoteDir, rel), filepath.Join(localDir, filepath.FromSlash(rel)))
+		})
+	}
+	if err != nil {
+		printError(err)
+		return
+	}
+
+	for _, rel := range plan.copy {
+		printMessage("copy %v\n", rel)
+	}
+	for _, rel := range plan.delete {
+		printMessage("delete %v\n", rel)
+	}
+	if *dryRun {
+		if len(plan.copy) == 0 && len(plan.delete) == 0 {
+			printMessage("Nothing to sync.\n")
+		}
+		outputFileResults(append(pathResults(plan.copy, "copy", "", nil), pathResults(plan.delete, "delete", "", nil)...))
+		return
+	}
+
+	var failed map[string]error
+	var deleteResults []fileResult
+	if *reverse {
+		failed = runSyncJobs(plan.copy, *jobs, func(rel string) error {
+			target := filepath.Join(localDir, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if _, err := downloadFile(client, path.Join(remoteDir, rel), target, "", false); err != nil {
+				return err
+			}
+			mtime := remote[rel].ModTime()
+			return os.Chtimes(target, mtime, mtime)
+		})
+		for _, rel := range plan.delete {
+			err := os.RemoveAll(filepath.Join(localDir, filepath.FromSlash(rel)))
+			if err != nil {
+				printError(err)
+			}
+			deleteResults = append(deleteResults, newFileResult(rel, "deleted", "", err))
+		}
+	} else {
+		opts := synoclient.UploadOptions{CreateParents: true, Conflict: synoclient.ConflictOverwrite}
+		failed = runSyncJobs(plan.copy, *jobs, func(rel string) error {
+			job := uploadJob{
+				local:     filepath.Join(localDir, filepath.FromSlash(rel)),
+				remoteDir: path.Join(remoteDir, path.Dir(rel)),
+			}
+			return uploadFile(client, job, opts, false)
+		})
+		if len(plan.delete) > 0 {
+			err := deleteRemote(client, remoteDir, plan.delete)
+			if err != nil {
+				printError(err)
+			}
+			deleteResults = pathResults(plan.delete, "deleted", "", err)
+		}
+	}
+
+	var results []fileResult
+	for _, rel := range plan.copy {
+		results = append(results, newFileResult(rel, "copied", "", failed[rel]))
+	}
+	deleteFailed := 0
+	for _, result := range deleteResults {
+		if result.Error != "" {
+			deleteFailed++
+		}
+	}
+	results = append(results, deleteResults...)
+
+	printMessage("%v file(s) copied, %v deleted, %v failed.\n", len(plan.copy)-len(failed), len(plan.delete)-deleteFailed, len(failed)+deleteFailed)
+	outputFileResults(results)
+}
+
+// scanTree returns files and folders of fsys by relative path. Files not
+// matching the include and exclude patterns are left out.
+func scanTree(fsys fs.FS, opts syncOptions) (map[string]fs.FileInfo, error) {
+	tree := make(map[string]fs.FileInfo)
+	err := fs.WalkDir(fsys, ".", func(rel string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if !d.IsDir() && !opts.matches(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		tree[rel] = info
+		return nil
+	})
+	return tree, err
+}
+
+// matches reports whether the file is included by the patterns
+func (opts syncOptions) matches(rel string) bool {
+	if matchTaskFile(opts.exclude, rel) {
+		return false
+	}
+	return len(opts.include) == 0 || matchTaskFile(opts.include, rel)
+}
+
+// planSync compares source and destination trees. Files of equal size are
+// compared by modification time or, with checksum, by sameChecksum.
+func planSync(src, dst map[string]fs.FileInfo, opts syncOptions, sameChecksum func(rel string) (bool, error)) (syncPlan, error) {
+	var plan syncPlan
+	for rel, info := range src {
+		if info.IsDir() {
+			continue
+		}
+		target, ok := dst[rel]
+		switch {
+		case !ok || target.IsDir() || target.Size() != info.Size():
+			plan.copy = append(plan.copy, rel)
+		case opts.checksum:
+			same, err := sameChecksum(rel)
+			if err != nil {
+				return plan, err
+			}
+			if !same {
+				plan.copy = append(plan.copy, rel)
+			}
+		// FileStation keeps seconds only
+		case absDuration(target.ModTime().Sub(info.ModTime())) > time.Second:
+			plan.copy = append(plan.copy, rel)
+		}
+	}
+
+	if opts.delete {
+		for rel, info := range dst {
+			if source, ok := src[rel]; ok && source.IsDir() == info.IsDir() {
+				continue
+			}
+			// contents of deleted folders go with them
+			if _, ok := src[path.Dir(rel)]; path.Dir(rel) != "." && !ok {
+				continue
+			}
+			plan.delete = append(plan.delete, rel)
+		}
+	}
+
+	sort.Strings(plan.copy)
+	sort.Strings(plan.delete)
+	return plan, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// sameChecksum compares MD5 hashes of a remote and local file
+func sameChecksum(client *synoclient.Client, remote string, local string) (bool, error) {
+	file, err := os.Open(local)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return false, err
+	}
+
+	task, err := client.MD5(remote)
+	if err != nil {
+		return false, err
+	}
+	status, err := waitResultTask(task)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(status.MD5, hex.EncodeToString(hash.Sum(nil))), nil
+}
+
+// deleteRemote deletes relative paths within remoteDir
+func deleteRemote(client *synoclient.Client, remoteDir string, rels []string) error {
+	var paths []string
+	for _, rel := range rels {
+		paths = append(paths, path.Join(remoteDir, rel))
+	}
+	task, err := client.Delete(paths, true)
+	if err != nil {
+		return err
+	}
+	_, err = task.Wait(context.Background())
+	return err
+}
+
+// syncError is a failed transfer of a relative path
+type syncError struct {
+	rel string
+	err error
+}
+
+// runSyncJobs runs transfer for every relative path using a pool of
+// workers and returns the failures by relative path
+func runSyncJobs(rels []string, noOfWorkers int, transfer func(rel string) error) map[string]error {
+	var processWg sync.WaitGroup
+	var errorWg sync.WaitGroup
+
+	processWg.Add(noOfWorkers)
+	errorWg.Add(1)
+	jobQueue := make(chan string, noOfWorkers)
+	errorQueue := make(chan syncError)
+
+	// create workers
+	for gr := 1; gr <= noOfWorkers; gr++ {
+		go func() {
+			defer processWg.Done()
+			for rel := range jobQueue {
+				if err := transfer(rel); err != nil {
+					errorQueue <- syncError{rel: rel, err: err}
+				}
+			}
+		}()
+	}
+
+	// read the error queue
+	failed := make(map[string]error)
+	go func() {
+		defer errorWg.Done()
+		for e := range errorQueue {
+			failed[e.rel] = e.err
+			printErrorf("%v not copied: %v\n", e.rel, e.err)
+		}
+	}()
+
+	for _, rel := range rels {
+		jobQueue <- rel
+	}
+
+	close(jobQueue)
+	processWg.Wait()
+
+	close(errorQueue)
+	errorWg.Wait()
+
+	return failed
+}
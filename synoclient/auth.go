@@ -1,5 +1,7 @@
 package synoclient
 
+import "time"
+
 //"github.com/pkg/errors"
 
 var AuthSynoErrors = map[int]string{
@@ -11,6 +13,12 @@ var AuthSynoErrors = map[int]string{
 }
 
 func (c *Client) Login() (sid string, err error) {
+	if c.OnLogin != nil {
+		defer func(start time.Time) {
+			c.OnLogin(time.Since(start), err)
+		}(time.Now())
+	}
+
 	loginParams := map[string]string{
 		"api":     "SYNO.API.Auth",
 		"version": "2",
@@ -29,7 +37,7 @@ func (c *Client) Login() (sid string, err error) {
 	sid = data.(map[string]interface{})["sid"].(string)
 
 	// set the sid field to pass with any subsequent request
-	c.Sid = sid
+	c.setSid(sid)
 	return sid, nil
 
 }
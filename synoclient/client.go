@@ -1,10 +1,15 @@
 package synoclient
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,6 +22,22 @@ type Client struct {
 	Session  string
 	Timeout  time.Duration
 	Sid      string
+	// KeepSession logs in again and retries a request once if the session
+	// expired or was taken over, for long running processes
+	KeepSession bool
+	// OnLogin is called after every login with its duration and result,
+	// e.g. to collect metrics
+	OnLogin func(duration time.Duration, err error)
+
+	sidMu   sync.RWMutex
+	loginMu sync.Mutex
+}
+
+// sessionErrors mean the request may succeed after logging in again
+var sessionErrors = map[int]bool{
+	106: true,
+	107: true,
+	119: true,
 }
 
 // NewRequest ...
@@ -34,8 +55,8 @@ func (c *Client) NewRequest(method string, path string, params map[string]string
 	}
 
 	// pack _sid param to each request if logged-in
-	if c.Sid != "" {
-		query.Set("_sid", c.Sid)
+	if sid := c.sid(); sid != "" {
+		query.Set("_sid", sid)
 	}
 
 	url.RawQuery = query.Encode()
@@ -53,9 +74,24 @@ func (c *Client) NewRequest(method string, path string, params map[string]string
 // Do ...
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	timeout := time.Duration(c.Timeout * time.Second)
-	httpClient := http.Client{
+	httpClient := &http.Client{
 		Timeout: timeout,
 	}
+	return c.do(httpClient, req)
+}
+
+// DoStream makes a request without overall timeout for transfers of any
+// size, the timeout still limits waiting for the response headers
+func (c *Client) DoStream(req *http.Request) (*http.Response, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = time.Duration(c.Timeout * time.Second)
+	httpClient := &http.Client{
+		Transport: transport,
+	}
+	return c.do(httpClient, req)
+}
+
+func (c *Client) do(httpClient *http.Client, req *http.Request) (*http.Response, error) {
 	resp, err := httpClient.Do(req)
 	//fmt.Printf("\nResponse: %v\n", resp)
 	if err != nil {
@@ -63,13 +99,72 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, &GenericError{desc: resp.Status}
+		resp.Body.Close()
+		return nil, &GenericError{desc: resp.Status, status: resp.StatusCode}
 	}
 	return resp, err
 }
 
 // Get ...
 func (c *Client) Get(path string, params map[string]string) (string, error) {
+	var resp string
+	err := c.keepSession(params, func() (err error) {
+		resp, err = c.get(path, params)
+		return err
+	})
+	return resp, err
+}
+
+// GetStream requests content of any size, e.g. a file download. Failures
+// reported as JSON instead of content are returned as error together with
+// the response body. The caller must close the returned response.
+func (c *Client) GetStream(path string, params map[string]string, header http.Header) (*http.Response, string, error) {
+	var resp *http.Response
+	var body string
+	err := c.keepSession(params, func() error {
+		req, err := c.NewRequest("GET", path, params)
+		if err != nil {
+			return err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err = c.DoStream(req)
+		if err != nil {
+			return err
+		}
+		if resp.Header.Get("Content-Disposition") == "" && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+			r := resp
+			resp = nil
+			body, err = c.readResponse(r)
+			if err == nil {
+				err = &GenericError{desc: "Unexpected response " + body}
+			}
+			return err
+		}
+		return nil
+	})
+	return resp, body, err
+}
+
+// keepSession runs request and, if KeepSession is set and the session
+// expired, logs in again and runs it once more. request must build a new
+// request on every call to use the new session.
+func (c *Client) keepSession(params map[string]string, request func() error) error {
+	sid := c.sid()
+	err := request()
+	if err == nil || !c.KeepSession || params["api"] == "SYNO.API.Auth" || !sessionErrors[ErrorCode(err)] {
+		return err
+	}
+
+	if err := c.relogin(sid); err != nil {
+		return err
+	}
+	return request()
+}
+
+func (c *Client) get(path string, params map[string]string) (string, error) {
 
 	// assemble the request
 	req, err := c.NewRequest("GET", path, params)
@@ -83,6 +178,107 @@ func (c *Client) Get(path string, params map[string]string) (string, error) {
 		return "", err
 	}
 
+	return c.readResponse(resp)
+}
+
+func (c *Client) sid() string {
+	c.sidMu.RLock()
+	defer c.sidMu.RUnlock()
+	return c.Sid
+}
+
+func (c *Client) setSid(sid string) {
+	c.sidMu.Lock()
+	defer c.sidMu.Unlock()
+	c.Sid = sid
+}
+
+// relogin replaces the expired session, unless a concurrent request
+// already did
+func (c *Client) relogin(expired string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	if c.sid() != expired {
+		return nil
+	}
+	_, err := c.Login()
+	return err
+}
+
+// PostMultipart streams params followed by the file content as multipart
+// form. size is the length of content, Synology APIs which require
+// Content-Length fail if it is unknown (-1).
+func (c *Client) PostMultipart(path string, params map[string]string, field string, filename string, content io.Reader, size int64) (string, error) {
+	// content is sent again after renewing the session if it can be
+	// rewound
+	start := int64(-1)
+	if seeker, ok := content.(io.Seeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			start = offset
+		}
+	}
+
+	var resp string
+	attempt := 0
+	err := c.keepSession(params, func() (err error) {
+		if attempt++; attempt > 1 {
+			if start < 0 {
+				return &GenericError{desc: "Session expired during upload"}
+			}
+			if _, err := content.(io.Seeker).Seek(start, io.SeekStart); err != nil {
+				return &GenericError{desc: err.Error()}
+			}
+		}
+		resp, err = c.postMultipart(path, params, field, filename, content, size)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) postMultipart(path string, params map[string]string, field string, filename string, content io.Reader, size int64) (string, error) {
+
+	// assemble the request, parameters go to the form
+	req, err := c.NewRequest("POST", path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// the form is written around the content so it can be streamed
+	// with known length
+	var prefix, suffix bytes.Buffer
+	form := multipart.NewWriter(&prefix)
+	for param, value := range params {
+		if err := form.WriteField(param, value); err != nil {
+			return "", err
+		}
+	}
+	// the file has to be the last part
+	if _, err := form.CreateFormFile(field, filename); err != nil {
+		return "", err
+	}
+	headerLength := prefix.Len()
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+	suffix.Write(prefix.Bytes()[headerLength:])
+	prefix.Truncate(headerLength)
+
+	req.Body = ioutil.NopCloser(io.MultiReader(&prefix, content, &suffix))
+	if size >= 0 {
+		req.ContentLength = int64(prefix.Len()) + size + int64(suffix.Len())
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	// make the call
+	resp, err := c.DoStream(req)
+	if err != nil {
+		return "", err
+	}
+
+	return c.readResponse(resp)
+}
+
+func (c *Client) readResponse(resp *http.Response) (string, error) {
 	// read response
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -124,3 +320,37 @@ func (c *Client) GetData(data string) interface{} {
 	json.Unmarshal([]byte(data), &responseData)
 	return responseData.(map[string]interface{})["data"]
 }
+
+// optionalInt returns a numeric field which may be missing in the response
+func optionalInt(data map[string]interface{}, key string) int64 {
+	if value, ok := data[key].(float64); ok {
+		return int64(value)
+	}
+	return 0
+}
+
+// optionalTime returns a unix timestamp field, zero timestamps are not set
+func optionalTime(data map[string]interface{}, key string) time.Time {
+	if value := optionalInt(data, key); value > 0 {
+		return time.Unix(value, 0)
+	}
+	return time.Time{}
+}
+
+// optionalString returns a string field which may be missing in the response
+func optionalString(data map[string]interface{}, key string) string {
+	value, _ := data[key].(string)
+	return value
+}
+
+// optionalBool returns a boolean field which may be missing in the response
+func optionalBool(data map[string]interface{}, key string) bool {
+	value, _ := data[key].(bool)
+	return value
+}
+
+// optionalMap returns an object field which may be missing in the response
+func optionalMap(data map[string]interface{}, key string) map[string]interface{} {
+	value, _ := data[key].(map[string]interface{})
+	return value
+}
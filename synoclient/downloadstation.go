@@ -1,36 +1,73 @@
 package synoclient
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 type DownloadStationTask struct {
-	ID                string
-	Type              string
-	Size              int64
-	Status            string
-	Title             string
-	Username          string
-	AdditinalTaskInfo AdditinalTaskInfo
+	ID                string            `json:"id"`
+	Type              string            `json:"type"`
+	Size              int64             `json:"size"`
+	Status            string            `json:"status"`
+	Title             string            `json:"title"`
+	Username          string            `json:"username"`
+	AdditinalTaskInfo AdditinalTaskInfo `json:"additional"`
 }
 
 type AdditinalTaskInfo struct {
-	TaskTransfer TaskTransfer
-	TaskDetail   TaskDetail
+	TaskTransfer TaskTransfer `json:"transfer"`
+	TaskDetail   TaskDetail   `json:"detail"`
 }
 
 type TaskTransfer struct {
-	SizeDownloaded int64
-	SpeedDownload  int64
+	SizeDownloaded int64 `json:"size_downloaded"`
+	SizeUploaded   int64 `json:"size_uploaded"`
+	SpeedDownload  int64 `json:"speed_download"`
+	SpeedUpload    int64 `json:"speed_upload"`
 }
 
 type TaskDetail struct {
-	Destination string
-	Uri         string
+	Destination string `json:"destination"`
+	Uri         string `json:"uri"`
+	// times are zero if not reported by Download Station
+	CreateTime    time.Time     `json:"create_time"`
+	StartedTime   time.Time     `json:"started_time"`
+	CompletedTime time.Time     `json:"completed_time"`
+	SeedElapsed   time.Duration `json:"-"`
+	// SeedElapsedSeconds is SeedElapsed for structured output
+	SeedElapsedSeconds int64 `json:"seed_elapsed_seconds"`
 }
 
+// TaskFile is a single file of a BT download task
+type TaskFile struct {
+	Index          int    `json:"index"`
+	Name           string `json:"name"`
+	Size           int64  `json:"size"`
+	SizeDownloaded int64  `json:"size_downloaded"`
+	Wanted         bool   `json:"wanted"`
+	Priority       string `json:"priority"`
+}
+
+// DownloadStationStatistic is the total transfer speed in bytes per second
+type DownloadStationStatistic struct {
+	SpeedDownload int64 `json:"speed_download"`
+	SpeedUpload   int64 `json:"speed_upload"`
+	// eMule transfers, zero if eMule is disabled
+	EmuleSpeedDownload int64 `json:"emule_speed_download"`
+	EmuleSpeedUpload   int64 `json:"emule_speed_upload"`
+}
+
+// Output receives progress messages, e.g. of task creation
+var Output io.Writer = os.Stdout
+
 type TaskAddError struct {
 	Name string
 	Err  error
@@ -113,21 +150,88 @@ func (c *Client) ListDownloadStationTasks() ([]DownloadStationTask, error) {
 
 }
 
+// GetDownloadStationStatistic returns the total transfer speed
+func (c *Client) GetDownloadStationStatistic() (DownloadStationStatistic, error) {
+	params := map[string]string{
+		"api":     "SYNO.DownloadStation.Statistic",
+		"version": "1",
+		"method":  "getinfo",
+	}
+
+	resp, err := c.Get("webapi/DownloadStation/statistic.cgi", params)
+	if err != nil {
+		return DownloadStationStatistic{}, HandleApplicationError(resp, err, DsSynoErrors)
+	}
+
+	data, _ := c.GetData(resp).(map[string]interface{})
+	return DownloadStationStatistic{
+		SpeedDownload:      optionalInt(data, "speed_download"),
+		SpeedUpload:        optionalInt(data, "speed_upload"),
+		EmuleSpeedDownload: optionalInt(data, "emule_speed_download"),
+		EmuleSpeedUpload:   optionalInt(data, "emule_speed_upload"),
+	}, nil
+}
+
+// ErrDuplicateTask is reported for URIs which already have a task
+var ErrDuplicateTask = errors.New("Task already exists")
+
 func (c *Client) CreateDownloadStationTask(fileQueue <-chan string, errorQueue chan<- *TaskAddError, wg *sync.WaitGroup) error {
+	return c.CreateUniqueDownloadStationTask(nil, fileQueue, errorQueue, wg)
+}
+
+// CreateUniqueDownloadStationTask validates URIs before creating tasks and
+// skips URIs already present in index. A nil index disables duplicate detection.
+func (c *Client) CreateUniqueDownloadStationTask(index *TaskIndex, fileQueue <-chan string, errorQueue chan<- *TaskAddError, wg *sync.WaitGroup) error {
+	defer wg.Done()
+	for filename := range fileQueue {
+		// skip blank lines of list files
+		if strings.TrimSpace(filename) == "" {
+			continue
+		}
+		if err := c.AddDownloadStationTask(index, filename); err != nil {
+			errorQueue <- &TaskAddError{Name: filename, Err: err}
+		}
+	}
+	return nil
+}
+
+// AddDownloadStationTask validates a URI and creates a task for it. URIs
+// already present in index fail with ErrDuplicateTask, a nil index
+// disables duplicate detection.
+func (c *Client) AddDownloadStationTask(index *TaskIndex, rawURI string) error {
+	uri, err := ParseTaskURI(rawURI)
+	if err != nil {
+		return err
+	}
+	if index != nil && !index.Add(uri) {
+		return ErrDuplicateTask
+	}
 
 	params := map[string]string{
 		"api":     "SYNO.DownloadStation.Task",
 		"version": "1",
 		"method":  "create",
+		"uri":     uri.Raw,
 	}
-	defer wg.Done()
-	for filename := range fileQueue {
-		params["uri"] = filename
-		fmt.Printf("Adding %v\n", truncateString(filename, 70))
-		resp, err := c.Get("webapi/DownloadStation/task.cgi", params)
-		if err != nil {
-			errorQueue <- &TaskAddError{Name: filename, Err: HandleApplicationError(resp, err, DsSynoErrors)}
-		}
+	fmt.Fprintf(Output, "Adding %v\n", truncateString(rawURI, 70))
+	resp, err := c.Get("webapi/DownloadStation/task.cgi", params)
+	if err != nil {
+		return HandleApplicationError(resp, err, DsSynoErrors)
+	}
+	return nil
+}
+
+// CreateDownloadStationTaskFromFile creates a task from a .torrent or .nzb file
+// of the given size
+func (c *Client) CreateDownloadStationTaskFromFile(filename string, content io.Reader, size int64) error {
+	params := map[string]string{
+		"api":     "SYNO.DownloadStation.Task",
+		"version": "1",
+		"method":  "create",
+	}
+	resp, err := c.PostMultipart("webapi/DownloadStation/task.cgi", params, "file", filename, content, size)
+	if err != nil {
+		return HandleApplicationError(resp, err, DsSynoErrors)
 	}
 	return nil
 }
@@ -174,6 +278,81 @@ func (c *Client) ResumeDownloadStationTasks(taskIds string) (response string, er
 	return resp, nil
 }
 
+// EditDownloadStationTasks changes the destination of existing tasks
+func (c *Client) EditDownloadStationTasks(taskIds string, destination string) (response string, err error) {
+	params := map[string]string{
+		"api": "SYNO.DownloadStation.Task",
+		// edit is available from version 2
+		"version": "2",
+		"method":  "edit",
+		"id":      taskIds,
+		// destination starts with a shared folder, without leading slash
+		"destination": strings.TrimPrefix(destination, "/"),
+	}
+	resp, err := c.Get("webapi/DownloadStation/task.cgi", params)
+	if err != nil {
+		return "", HandleApplicationError(resp, err, DsSynoErrors)
+	}
+	return resp, nil
+}
+
+// ListDownloadStationTaskFiles returns files of a BT task
+func (c *Client) ListDownloadStationTaskFiles(taskID string) ([]TaskFile, error) {
+	// DownloadStation2 expects JSON encoded parameter values
+	params := map[string]string{
+		"api":     "SYNO.DownloadStation2.Task.BT.File",
+		"version": "2",
+		"method":  "list",
+		"task_id": strconv.Quote(taskID),
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, HandleApplicationError(resp, err, DsSynoErrors)
+	}
+
+	items := c.GetData(resp).(map[string]interface{})["items"].([]interface{})
+	var files []TaskFile
+	for _, item := range items {
+		f := item.(map[string]interface{})
+		files = append(files, TaskFile{
+			Index:          int(f["index"].(float64)),
+			Name:           f["name"].(string),
+			Size:           int64(f["size"].(float64)),
+			SizeDownloaded: int64(f["size_downloaded"].(float64)),
+			Wanted:         f["wanted"].(bool),
+			Priority:       f["priority"].(string),
+		})
+	}
+	return files, nil
+}
+
+// SetDownloadStationTaskFiles enables or disables files of a BT task and sets
+// their priority. Empty priority keeps the current one.
+func (c *Client) SetDownloadStationTaskFiles(taskID string, indexes []int, wanted bool, priority string) error {
+	index, err := json.Marshal(indexes)
+	if err != nil {
+		return err
+	}
+	params := map[string]string{
+		"api":     "SYNO.DownloadStation2.Task.BT.File",
+		"version": "2",
+		"method":  "set",
+		"task_id": strconv.Quote(taskID),
+		"index":   string(index),
+		"wanted":  strconv.FormatBool(wanted),
+	}
+	if priority != "" {
+		params["priority"] = strconv.Quote(priority)
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return HandleApplicationError(resp, err, DsSynoErrors)
+	}
+	return nil
+}
+
 func mapDownloadStationTask(tasks []interface{}) []DownloadStationTask {
 	var downloadTasks []DownloadStationTask
 	for _, task := range tasks {
@@ -192,11 +371,18 @@ func mapDownloadStationTask(tasks []interface{}) []DownloadStationTask {
 				AdditinalTaskInfo: AdditinalTaskInfo{
 					TaskTransfer: TaskTransfer{
 						SizeDownloaded: int64(transferInfo["size_downloaded"].(float64)),
+						SizeUploaded:   optionalInt(transferInfo, "size_uploaded"),
 						SpeedDownload:  int64(transferInfo["speed_download"].(float64)),
+						SpeedUpload:    optionalInt(transferInfo, "speed_upload"),
 					},
 					TaskDetail: TaskDetail{
-						Destination: detailInfo["destination"].(string),
-						Uri:         detailInfo["uri"].(string),
+						Destination:        detailInfo["destination"].(string),
+						Uri:                detailInfo["uri"].(string),
+						CreateTime:         optionalTime(detailInfo, "create_time"),
+						StartedTime:        optionalTime(detailInfo, "started_time"),
+						CompletedTime:      optionalTime(detailInfo, "completed_time"),
+						SeedElapsed:        time.Duration(optionalInt(detailInfo, "seedelapsed")) * time.Second,
+						SeedElapsedSeconds: optionalInt(detailInfo, "seedelapsed"),
 					},
 				},
 			})
@@ -0,0 +1,36 @@
+package synoclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestEditDownloadStationTasks(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":[{"id":"dbid_1","error":0}]}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	c := &Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	if _, err := c.EditDownloadStationTasks("dbid_1", "/video/movies"); err != nil {
+		t.Fatalf("EditDownloadStationTasks: %v", err)
+	}
+	want := map[string]string{
+		"api":         "SYNO.DownloadStation.Task",
+		"version":     "2",
+		"method":      "edit",
+		"id":          "dbid_1",
+		"destination": "video/movies",
+	}
+	for param, value := range want {
+		if got := query.Get(param); got != value {
+			t.Errorf("parameter %v = %q, want %q", param, got, value)
+		}
+	}
+}
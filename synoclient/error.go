@@ -15,6 +15,8 @@ type CommonSynoError struct {
 // GenericError ...
 type GenericError struct {
 	desc string
+	// status is the HTTP status of failed responses
+	status int
 }
 
 type ApplicationError struct {
@@ -31,6 +33,7 @@ var commonSynoErrors = map[int]string{
 	105: "The logged in session does not have permission",
 	106: "Session timeout",
 	107: "Session interrupted by duplicate login",
+	119: "SID not found",
 }
 
 func (synoerror *ApplicationError) Error() string {
@@ -42,7 +45,7 @@ func HandleCommonSynoError(responseData interface{}) error {
 	// error -> code
 	errorCode := int(errorBlock.(map[string]interface{})["code"].(float64))
 
-	// check if we are handling common Syno errors (100-107)
+	// check if we are handling common Syno errors (100-107, 119)
 	if _, ok := commonSynoErrors[errorCode]; ok {
 		return &CommonSynoError{code: errorCode}
 	}
@@ -80,3 +83,22 @@ func (synoerror *CommonSynoError) Error() string {
 func (genericerror *GenericError) Error() string {
 	return fmt.Sprintf("Error occured: %v", genericerror.desc)
 }
+
+// Code returns the Synology error code
+func (synoerror *ApplicationError) Code() int {
+	return synoerror.code
+}
+
+// Code returns the Synology error code
+func (synoerror *CommonSynoError) Code() int {
+	return synoerror.code
+}
+
+// ErrorCode returns the Synology error code carried by err or 0
+func ErrorCode(err error) int {
+	var coded interface{ Code() int }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return 0
+}
@@ -1,8 +1,10 @@
 package synoclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -15,8 +17,31 @@ type FsSpecificError struct {
 }
 
 var FsSynoErrors = map[int]string{
+	900:  "Failed to delete file(s)/folder(s)",
+	1000: "Failed to copy files/folders",
+	1001: "Failed to move files/folders",
 	1002: "An error occurred at the destination",
+	1003: "Cannot overwrite or skip the existing file because no overwrite parameter is given",
+	1004: "File cannot overwrite a folder with the same name, or folder cannot overwrite a file with the same name",
+	1006: "Cannot copy/move file/folder with special characters to a FAT32 file system",
+	1007: "Cannot copy/move a file bigger than 4G to a FAT32 file system",
+	1100: "Failed to create a folder",
+	1101: "The number of folders to the parent folder would exceed the system limitation",
 	1200: "Failed to rename file",
+	1300: "Failed to compress files/folders",
+	1301: "Cannot create the archive because the given archive name is too long",
+	1400: "Failed to extract files",
+	1401: "Cannot open the file as archive",
+	1402: "Failed to read archive data",
+	1403: "Wrong password",
+	1404: "Failed to get the file and dir list in an archive",
+	1405: "Failed to find the item ID in an archive file",
+	1800: "There is no Content-Length information in the HTTP header or the received size doesn't match the value of Content-Length",
+	1801: "Wait too long, no data can be received from client",
+	1802: "No filename information in the last part of file content",
+	1803: "Upload connection is cancelled",
+	1804: "Failed to upload oversized file to FAT file system",
+	1805: "Can't overwrite or skip the existing file, if no overwrite parameter is given",
 	// more to come
 }
 
@@ -46,13 +71,44 @@ var FsSpecifiErrors = map[int]string{
 	599: "No such task of the file operation",
 }
 
+// fsErrorCodes holds both file operation and API specific error codes,
+// file operation codes may be returned directly by any FileStation API
+var fsErrorCodes = func() map[int]string {
+	codes := make(map[int]string)
+	for code, reason := range FsSpecifiErrors {
+		codes[code] = reason
+	}
+	for code, reason := range FsSynoErrors {
+		codes[code] = reason
+	}
+	for code, reason := range FsSharingErrors {
+		codes[code] = reason
+	}
+	return codes
+}()
+
+// fsError converts a failed FileStation response to error
+func fsError(response string, err error) error {
+	return specifyError(response, HandleApplicationError(response, err, fsErrorCodes))
+}
+
 func specifyError(response string, err error) error {
-	var responseData interface{}
-	json.Unmarshal([]byte(response), &responseData)
-	errorBlock := responseData.(map[string]interface{})["error"]
+	var responseData map[string]interface{}
+	if json.Unmarshal([]byte(response), &responseData) != nil {
+		return err
+	}
 	// error -> errors{[0]} -> code
-	nestedErrorCode := int(errorBlock.(map[string]interface{})["errors"].([]interface{})[0].(map[string]interface{})["code"].(float64))
-	return errors.Wrap(err, FsSpecifiErrors[nestedErrorCode])
+	errorBlock, _ := responseData["error"].(map[string]interface{})
+	nestedErrors, _ := errorBlock["errors"].([]interface{})
+	if len(nestedErrors) == 0 {
+		return err
+	}
+	nestedError, _ := nestedErrors[0].(map[string]interface{})
+	nestedErrorCode, ok := nestedError["code"].(float64)
+	if !ok {
+		return err
+	}
+	return errors.Wrap(err, FsSpecifiErrors[int(nestedErrorCode)])
 }
 
 func (fserror *FsSpecificError) Error() string {
@@ -70,28 +126,65 @@ func (c *Client) RenameFile(path string, name string) (string, error) {
 
 	resp, err := c.Get("webapi/entry.cgi", params)
 	if err != nil {
-		return "", specifyError(resp, HandleApplicationError(resp, err, FsSynoErrors))
+		return "", fsError(resp, err)
 	}
 
 	return string(c.GetData(resp).(map[string]interface{})["files"].([]interface{})[0].(map[string]interface{})["path"].(string)), nil
 }
 
-func (c *Client) MoveFile(sourceFile string, destinationDir string) error {
+// ConflictMode decides what happens to existing files at the destination
+type ConflictMode int
+
+const (
+	// ConflictError fails the operation
+	ConflictError ConflictMode = iota
+	// ConflictOverwrite replaces existing files
+	ConflictOverwrite
+	// ConflictSkip keeps existing files
+	ConflictSkip
+)
+
+// CopyMoveOptions are optional parameters of Copy and Move
+type CopyMoveOptions struct {
+	Conflict ConflictMode
+	// AccurateProgress calculates the progress of each file, which is slower
+	AccurateProgress bool
+}
+
+// Copy starts copying files and folders to destinationDir
+func (c *Client) Copy(paths []string, destinationDir string, opts CopyMoveOptions) (*FileTask, error) {
+	return c.copyMove(paths, destinationDir, opts, false)
+}
+
+// Move starts moving files and folders to destinationDir
+func (c *Client) Move(paths []string, destinationDir string, opts CopyMoveOptions) (*FileTask, error) {
+	return c.copyMove(paths, destinationDir, opts, true)
+}
+
+func (c *Client) copyMove(paths []string, destinationDir string, opts CopyMoveOptions, removeSource bool) (*FileTask, error) {
 	params := map[string]string{
-		"api":              "SYNO.FileStation.CopyMove",
-		"version":          "1",
-		"method":           "start",
-		"path":             sourceFile,
-		"dest_folder_path": destinationDir,
-		"remove_src":       "true",
+		"path":              pathList(paths),
+		"dest_folder_path":  destinationDir,
+		"remove_src":        strconv.FormatBool(removeSource),
+		"accurate_progress": strconv.FormatBool(opts.AccurateProgress),
+	}
+	switch opts.Conflict {
+	case ConflictOverwrite:
+		params["overwrite"] = "true"
+	case ConflictSkip:
+		params["overwrite"] = "false"
 	}
 
-	resp, err := c.Get("webapi/entry.cgi", params)
+	return c.startFileTask("SYNO.FileStation.CopyMove", "1", params)
+}
+
+// MoveFile moves sourceFile to destinationDir and waits until it is done
+func (c *Client) MoveFile(sourceFile string, destinationDir string) error {
+	task, err := c.Move([]string{sourceFile}, destinationDir, CopyMoveOptions{})
 	if err != nil {
-		fmt.Println(resp)
-		return specifyError(resp, HandleApplicationError(resp, err, FsSynoErrors))
+		return err
 	}
 
-	return nil
-
+	_, err = task.Wait(context.Background())
+	return err
 }
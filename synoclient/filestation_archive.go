@@ -0,0 +1,131 @@
+package synoclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// ArchiveItem is a file or folder within an archive
+type ArchiveItem struct {
+	ID       int           `json:"id"`
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Size     int64         `json:"size"`
+	PackSize int64         `json:"pack_size"`
+	Mtime    time.Time     `json:"mtime"`
+	IsDir    bool          `json:"isdir"`
+	Items    []ArchiveItem `json:"items,omitempty"`
+}
+
+// ExtractOptions are optional parameters of Extract
+type ExtractOptions struct {
+	Overwrite bool
+	// KeepDir keeps the folder structure of the archive
+	KeepDir bool
+	// CreateSubfolder extracts into a folder named after the archive
+	CreateSubfolder bool
+	Codepage        string
+	Password        string
+	// ItemIDs extracts only the given items, all items if empty
+	ItemIDs []int
+}
+
+// CompressOptions are optional parameters of Compress
+type CompressOptions struct {
+	// Format zip or 7z
+	Format string
+	// Level moderate, store, fastest or best
+	Level string
+	// Mode add, update, refreshen or synchronize
+	Mode     string
+	Password string
+}
+
+// ListArchive returns the items of an archive
+func (c *Client) ListArchive(filePath string, password string) ([]ArchiveItem, error) {
+	params := map[string]string{
+		"api":       "SYNO.FileStation.Extract",
+		"version":   "2",
+		"method":    "list",
+		"file_path": filePath,
+		"offset":    "0",
+		"limit":     "-1",
+	}
+	if password != "" {
+		params["password"] = password
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, fsError(resp, err)
+	}
+
+	items, _ := c.GetData(resp).(map[string]interface{})["items"].([]interface{})
+	return mapArchiveItems(items), nil
+}
+
+// Extract starts extracting an archive into destinationDir
+func (c *Client) Extract(filePath string, destinationDir string, opts ExtractOptions) (*FileTask, error) {
+	params := map[string]string{
+		"file_path":        filePath,
+		"dest_folder_path": destinationDir,
+		"overwrite":        strconv.FormatBool(opts.Overwrite),
+		"keep_dir":         strconv.FormatBool(opts.KeepDir),
+		"create_subfolder": strconv.FormatBool(opts.CreateSubfolder),
+	}
+	if opts.Codepage != "" {
+		params["codepage"] = opts.Codepage
+	}
+	if opts.Password != "" {
+		params["password"] = opts.Password
+	}
+	if len(opts.ItemIDs) > 0 {
+		itemIDs, _ := json.Marshal(opts.ItemIDs)
+		params["item_id"] = string(itemIDs)
+	}
+
+	return c.startFileTask("SYNO.FileStation.Extract", "2", params)
+}
+
+// Compress starts packing files and folders into the archive destinationFile
+func (c *Client) Compress(paths []string, destinationFile string, opts CompressOptions) (*FileTask, error) {
+	params := map[string]string{
+		"path":           pathList(paths),
+		"dest_file_path": destinationFile,
+	}
+	if opts.Format != "" {
+		params["format"] = opts.Format
+	}
+	if opts.Level != "" {
+		params["level"] = opts.Level
+	}
+	if opts.Mode != "" {
+		params["mode"] = opts.Mode
+	}
+	if opts.Password != "" {
+		params["password"] = opts.Password
+	}
+
+	return c.startFileTask("SYNO.FileStation.Compress", "3", params)
+}
+
+func mapArchiveItems(items []interface{}) []ArchiveItem {
+	var archiveItems []ArchiveItem
+	for _, item := range items {
+		i, _ := item.(map[string]interface{})
+		children, _ := i["items"].([]interface{})
+		mtime, _ := time.ParseInLocation("2006-01-02 15:04:05", optionalString(i, "mtime"), time.Local)
+		archiveItems = append(archiveItems, ArchiveItem{
+			ID:       int(optionalInt(i, "itemid")),
+			Name:     optionalString(i, "name"),
+			Path:     optionalString(i, "path"),
+			Size:     optionalInt(i, "size"),
+			PackSize: optionalInt(i, "pack_size"),
+			Mtime:    mtime,
+			IsDir:    optionalBool(i, "is_dir"),
+			Items:    mapArchiveItems(children),
+		})
+	}
+	return archiveItems
+}
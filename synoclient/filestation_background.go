@@ -0,0 +1,108 @@
+package synoclient
+
+import (
+	"strconv"
+	"time"
+)
+
+// BackgroundTask is a copy, move, delete, extract or compress task as
+// tracked by DSM
+type BackgroundTask struct {
+	API     string    `json:"api"`
+	Version string    `json:"version"`
+	Method  string    `json:"method"`
+	TaskID  string    `json:"task_id"`
+	Created time.Time `json:"created"`
+	// Params the task was started with
+	Params map[string]interface{} `json:"params"`
+	FileTaskStatus
+}
+
+// BackgroundTaskOptions filters and sorts ListBackgroundTasks
+type BackgroundTaskOptions struct {
+	Offset int
+	// Limit of returned tasks, 0 means all
+	Limit int
+	// SortBy crtime or finished
+	SortBy string
+	// SortDirection asc or desc
+	SortDirection string
+	// APIs limits the list to tasks of these APIs, e.g. SYNO.FileStation.CopyMove
+	APIs []string
+}
+
+// ListBackgroundTasks returns the FileStation background tasks of the user
+// and their total number
+func (c *Client) ListBackgroundTasks(opts BackgroundTaskOptions) ([]BackgroundTask, int, error) {
+	params := map[string]string{
+		"api":     "SYNO.FileStation.BackgroundTask",
+		"version": "3",
+		"method":  "list",
+		"offset":  strconv.Itoa(opts.Offset),
+	}
+	if opts.Limit > 0 {
+		params["limit"] = strconv.Itoa(opts.Limit)
+	}
+	if opts.SortBy != "" {
+		params["sort_by"] = opts.SortBy
+	}
+	if opts.SortDirection != "" {
+		params["sort_direction"] = opts.SortDirection
+	}
+	if len(opts.APIs) > 0 {
+		params["api_filter"] = pathList(opts.APIs)
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, 0, fsError(resp, err)
+	}
+
+	data, _ := c.GetData(resp).(map[string]interface{})
+	list, _ := data["tasks"].([]interface{})
+	var tasks []BackgroundTask
+	for _, item := range list {
+		task, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		version := optionalString(task, "version")
+		if version == "" {
+			version = strconv.FormatInt(optionalInt(task, "version"), 10)
+		}
+		tasks = append(tasks, BackgroundTask{
+			API:            optionalString(task, "api"),
+			Version:        version,
+			Method:         optionalString(task, "method"),
+			TaskID:         optionalString(task, "taskid"),
+			Created:        optionalTime(task, "crtime"),
+			Params:         optionalMap(task, "params"),
+			FileTaskStatus: *mapFileTaskStatus(task),
+		})
+	}
+	return tasks, int(optionalInt(data, "total")), nil
+}
+
+// Task returns the handle of the background task to poll, wait on or stop it
+func (t BackgroundTask) Task(c *Client) *FileTask {
+	return c.AttachFileTask(t.API, t.Version, t.TaskID)
+}
+
+// ClearFinishedBackgroundTasks removes finished tasks from the list, all of
+// them if no task ids are given
+func (c *Client) ClearFinishedBackgroundTasks(taskIDs []string) error {
+	params := map[string]string{
+		"api":     "SYNO.FileStation.BackgroundTask",
+		"version": "3",
+		"method":  "clear_finished",
+	}
+	if len(taskIDs) > 0 {
+		params["taskid"] = pathList(taskIDs)
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return fsError(resp, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package synoclient
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Favorite is a bookmarked folder of the user
+type Favorite struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+	// Status is valid or broken if the folder no longer exists
+	Status string `json:"status"`
+}
+
+func (c *Client) favorite(method string, params map[string]string) (string, error) {
+	params["api"] = "SYNO.FileStation.Favorite"
+	params["version"] = "2"
+	params["method"] = method
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return resp, fsError(resp, err)
+	}
+	return resp, nil
+}
+
+// ListFavorites returns the favorites of the user and their total number
+func (c *Client) ListFavorites(offset int, limit int) ([]Favorite, int, error) {
+	params := map[string]string{
+		"offset":        strconv.Itoa(offset),
+		"limit":         strconv.Itoa(limit),
+		"status_filter": "all",
+	}
+
+	resp, err := c.favorite("list", params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data := c.GetData(resp).(map[string]interface{})
+	list, _ := data["favorites"].([]interface{})
+	var favorites []Favorite
+	for _, item := range list {
+		favorite, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		favorites = append(favorites, Favorite{
+			Path:   optionalString(favorite, "path"),
+			Name:   optionalString(favorite, "name"),
+			Status: optionalString(favorite, "status"),
+		})
+	}
+	return favorites, int(optionalInt(data, "total")), nil
+}
+
+// AddFavorite bookmarks a folder under name
+func (c *Client) AddFavorite(folderPath string, name string) error {
+	_, err := c.favorite("add", map[string]string{"path": folderPath, "name": name, "index": "-1"})
+	return err
+}
+
+// RenameFavorite changes the name of a favorite
+func (c *Client) RenameFavorite(folderPath string, name string) error {
+	_, err := c.favorite("edit", map[string]string{"path": folderPath, "name": name})
+	return err
+}
+
+// DeleteFavorite removes a favorite, the folder itself is kept
+func (c *Client) DeleteFavorite(folderPath string) error {
+	_, err := c.favorite("delete", map[string]string{"path": folderPath})
+	return err
+}
+
+// ClearBrokenFavorites removes favorites whose folders no longer exist
+func (c *Client) ClearBrokenFavorites() error {
+	_, err := c.favorite("clear_broken", map[string]string{})
+	return err
+}
+
+// ReplaceFavorites replaces all favorites of the user
+func (c *Client) ReplaceFavorites(favorites []Favorite) error {
+	var paths, names []string
+	for _, favorite := range favorites {
+		paths = append(paths, favorite.Path)
+		names = append(names, favorite.Name)
+	}
+	nameList, _ := json.Marshal(names)
+
+	_, err := c.favorite("replace_all", map[string]string{"path": pathList(paths), "name": string(nameList)})
+	return err
+}
+
+// ListVirtualFolders returns mounted remote folders of type cifs, nfs or
+// iso and their total number
+func (c *Client) ListVirtualFolders(folderType string, opts ListOptions) ([]FileInfo, int, error) {
+	params := opts.params()
+	params["api"] = "SYNO.FileStation.VirtualFolder"
+	params["version"] = "2"
+	params["method"] = "list"
+	params["type"] = folderType
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, 0, fsError(resp, err)
+	}
+
+	data := c.GetData(resp).(map[string]interface{})
+	folders, _ := data["folders"].([]interface{})
+	return mapFileInfo(folders), int(optionalInt(data, "total")), nil
+}
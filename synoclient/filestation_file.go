@@ -0,0 +1,131 @@
+package synoclient
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// CreateFolder creates folder name in folderPath. With forceParent missing
+// parent folders are created and existing folders are no error.
+func (c *Client) CreateFolder(folderPath string, name string, forceParent bool) (FileInfo, error) {
+	params := map[string]string{
+		"api":          "SYNO.FileStation.CreateFolder",
+		"version":      "2",
+		"method":       "create",
+		"folder_path":  folderPath,
+		"name":         name,
+		"force_parent": strconv.FormatBool(forceParent),
+		"additional":   strings.Join(DefaultFileAdditional, ","),
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return FileInfo{}, fsError(resp, err)
+	}
+
+	folders := mapFileInfo(c.GetData(resp).(map[string]interface{})["folders"].([]interface{}))
+	if len(folders) == 0 {
+		return FileInfo{}, &GenericError{desc: "No folder created"}
+	}
+	return folders[0], nil
+}
+
+// MkdirAll creates the folder and its missing parents
+func (c *Client) MkdirAll(folderPath string) (FileInfo, error) {
+	folderPath = path.Clean(folderPath)
+	// shared folders cannot be created
+	if path.Dir(folderPath) == "/" {
+		return c.Stat(folderPath)
+	}
+	return c.CreateFolder(path.Dir(folderPath), path.Base(folderPath), true)
+}
+
+// Delete starts deleting files and folders, non-empty folders are deleted
+// only if recursive
+func (c *Client) Delete(paths []string, recursive bool) (*FileTask, error) {
+	params := map[string]string{
+		"path":              pathList(paths),
+		"recursive":         strconv.FormatBool(recursive),
+		"accurate_progress": "true",
+	}
+
+	return c.startFileTask("SYNO.FileStation.Delete", "2", params)
+}
+
+// GetFileInfo returns info of files and folders
+func (c *Client) GetFileInfo(paths []string, additional []string) ([]FileInfo, error) {
+	params := map[string]string{
+		"api":        "SYNO.FileStation.List",
+		"version":    "2",
+		"method":     "getinfo",
+		"path":       pathList(paths),
+		"additional": strings.Join(additional, ","),
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, fsError(resp, err)
+	}
+
+	files := c.GetData(resp).(map[string]interface{})["files"].([]interface{})
+	// missing files are reported in place with error code
+	for _, file := range files {
+		if code := int(optionalInt(file.(map[string]interface{}), "code")); code != 0 {
+			return nil, &ApplicationError{code: code, reason: fsErrorCodes[code]}
+		}
+	}
+	return mapFileInfo(files), nil
+}
+
+// Stat returns info of a single file or folder
+func (c *Client) Stat(filePath string) (FileInfo, error) {
+	files, err := c.GetFileInfo([]string{filePath}, DefaultFileAdditional)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if len(files) == 0 {
+		return FileInfo{}, &ApplicationError{code: 408, reason: fsErrorCodes[408]}
+	}
+	return files[0], nil
+}
+
+// MD5 starts calculating the MD5 hash of a file, the hash is reported
+// in FileTaskStatus.MD5 once finished
+func (c *Client) MD5(filePath string) (*FileTask, error) {
+	params := map[string]string{
+		"file_path": filePath,
+	}
+
+	return c.startFileTask("SYNO.FileStation.MD5", "2", params)
+}
+
+// DirSize starts calculating the total size of files and folders, the
+// result is reported in FileTaskStatus.TotalSize, NumFile and NumDir
+func (c *Client) DirSize(paths []string) (*FileTask, error) {
+	params := map[string]string{
+		"path": pathList(paths),
+	}
+
+	return c.startFileTask("SYNO.FileStation.DirSize", "2", params)
+}
+
+// CheckPermission checks if the user may write filename into folderPath.
+// With createOnly existing files may not be overwritten.
+func (c *Client) CheckPermission(folderPath string, filename string, overwrite bool, createOnly bool) error {
+	params := map[string]string{
+		"api":         "SYNO.FileStation.CheckPermission",
+		"version":     "3",
+		"method":      "write",
+		"path":        folderPath,
+		"filename":    filename,
+		"overwrite":   strconv.FormatBool(overwrite),
+		"create_only": strconv.FormatBool(createOnly),
+	}
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return fsError(resp, err)
+	}
+	return nil
+}
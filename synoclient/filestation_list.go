@@ -0,0 +1,262 @@
+package synoclient
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileInfo is a file or folder returned by FileStation. Fields other than
+// Path, Name and IsDir are set only if requested in 'additional'.
+type FileInfo struct {
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	IsDir    bool   `json:"isdir"`
+	RealPath string `json:"real_path"`
+	Size     int64  `json:"size"`
+	// Type is the file extension, e.g. MKV
+	Type string `json:"type"`
+	// MountPointType is set for remote and ISO mount points, e.g. remote
+	MountPointType string    `json:"mount_point_type"`
+	Owner          FileOwner `json:"owner"`
+	Time           FileTime  `json:"time"`
+	Perm           FilePerm  `json:"perm"`
+	// Dimension of indexed images and videos
+	Dimension FileDimension `json:"dimension"`
+}
+
+// FileDimension is the size of an image or video in pixels, zero if not
+// known to the NAS
+type FileDimension struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type FileOwner struct {
+	User  string `json:"user"`
+	Group string `json:"group"`
+	UID   int    `json:"uid"`
+	GID   int    `json:"gid"`
+}
+
+type FileTime struct {
+	Atime  time.Time `json:"atime"`
+	Mtime  time.Time `json:"mtime"`
+	Ctime  time.Time `json:"ctime"`
+	Crtime time.Time `json:"crtime"`
+}
+
+type FilePerm struct {
+	Posix     int     `json:"posix"`
+	IsACLMode bool    `json:"is_acl_mode"`
+	ACL       FileACL `json:"acl"`
+}
+
+type FileACL struct {
+	Append bool `json:"append"`
+	Del    bool `json:"del"`
+	Exec   bool `json:"exec"`
+	Read   bool `json:"read"`
+	Write  bool `json:"write"`
+}
+
+// ListOptions are optional parameters of FileStation listings
+type ListOptions struct {
+	Offset int
+	// Limit of returned entries, 0 means all
+	Limit int
+	// SortBy name, size, user, group, mtime, atime, ctime, crtime, posix or type
+	SortBy string
+	// SortDirection asc or desc
+	SortDirection string
+	// Pattern is a comma separated list of glob patterns
+	Pattern string
+	// FileType file, dir or all
+	FileType string
+	// Additional info: real_path, size, owner, time, perm, type,
+	// mount_point_type, dimension
+	Additional []string
+}
+
+// DefaultFileAdditional requests all additional file info
+var DefaultFileAdditional = []string{"real_path", "size", "owner", "time", "perm", "type", "mount_point_type"}
+
+func (o ListOptions) params() map[string]string {
+	params := map[string]string{
+		"offset": strconv.Itoa(o.Offset),
+		"limit":  strconv.Itoa(o.Limit),
+	}
+	if o.SortBy != "" {
+		params["sort_by"] = o.SortBy
+	}
+	if o.SortDirection != "" {
+		params["sort_direction"] = o.SortDirection
+	}
+	if o.Pattern != "" {
+		params["pattern"] = o.Pattern
+	}
+	if o.FileType != "" {
+		params["filetype"] = o.FileType
+	}
+	if len(o.Additional) > 0 {
+		params["additional"] = strings.Join(o.Additional, ",")
+	}
+	return params
+}
+
+// ListShares returns shared folders and the total number of shares
+func (c *Client) ListShares(opts ListOptions) ([]FileInfo, int, error) {
+	params := opts.params()
+	params["api"] = "SYNO.FileStation.List"
+	params["version"] = "2"
+	params["method"] = "list_share"
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, 0, fsError(resp, err)
+	}
+
+	data := c.GetData(resp).(map[string]interface{})
+	shares, _ := data["shares"].([]interface{})
+	return mapFileInfo(shares), int(optionalInt(data, "total")), nil
+}
+
+// ListFolder returns files of a folder and the total number of files
+func (c *Client) ListFolder(folderPath string, opts ListOptions) ([]FileInfo, int, error) {
+	params := opts.params()
+	params["api"] = "SYNO.FileStation.List"
+	params["version"] = "2"
+	params["method"] = "list"
+	params["folder_path"] = folderPath
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, 0, fsError(resp, err)
+	}
+
+	data := c.GetData(resp).(map[string]interface{})
+	files, _ := data["files"].([]interface{})
+	return mapFileInfo(files), int(optionalInt(data, "total")), nil
+}
+
+// FileIterator pages through a folder listing
+//
+//	it := c.NewFileIterator("/video", ListOptions{})
+//	for it.Next() {
+//		fmt.Println(it.File().Name)
+//	}
+//	if it.Err() != nil { ... }
+type FileIterator struct {
+	c          *Client
+	folderPath string
+	opts       ListOptions
+	files      []FileInfo
+	current    FileInfo
+	total      int
+	done       bool
+	err        error
+}
+
+// defaultPageSize is used by FileIterator if ListOptions.Limit is not set
+const defaultPageSize = 1000
+
+// NewFileIterator iterates over the folder, or over shared folders if
+// folderPath is empty. opts.Limit is the page size.
+func (c *Client) NewFileIterator(folderPath string, opts ListOptions) *FileIterator {
+	if opts.Limit <= 0 {
+		opts.Limit = defaultPageSize
+	}
+	return &FileIterator{c: c, folderPath: folderPath, opts: opts}
+}
+
+// Next advances to the next file, fetching a new page if needed
+func (it *FileIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.files) == 0 && !it.done {
+		if it.folderPath == "" {
+			it.files, it.total, it.err = it.c.ListShares(it.opts)
+		} else {
+			it.files, it.total, it.err = it.c.ListFolder(it.folderPath, it.opts)
+		}
+		if it.err != nil {
+			return false
+		}
+		it.opts.Offset += len(it.files)
+		it.done = len(it.files) == 0 || it.opts.Offset >= it.total
+	}
+
+	if len(it.files) == 0 {
+		return false
+	}
+	it.current = it.files[0]
+	it.files = it.files[1:]
+	return true
+}
+
+// File returns the current file
+func (it *FileIterator) File() FileInfo {
+	return it.current
+}
+
+// Total returns the total number of files reported by FileStation
+func (it *FileIterator) Total() int {
+	return it.total
+}
+
+// Err returns the error which stopped the iteration
+func (it *FileIterator) Err() error {
+	return it.err
+}
+
+func mapFileInfo(files []interface{}) []FileInfo {
+	var fileInfos []FileInfo
+	for _, file := range files {
+		f := file.(map[string]interface{})
+		additional := optionalMap(f, "additional")
+		owner := optionalMap(additional, "owner")
+		times := optionalMap(additional, "time")
+		perm := optionalMap(additional, "perm")
+		acl := optionalMap(perm, "acl")
+		dimension := optionalMap(additional, "dimension")
+		fileInfos = append(fileInfos, FileInfo{
+			Path:           f["path"].(string),
+			Name:           f["name"].(string),
+			IsDir:          optionalBool(f, "isdir"),
+			RealPath:       optionalString(additional, "real_path"),
+			Size:           optionalInt(additional, "size"),
+			Type:           optionalString(additional, "type"),
+			MountPointType: optionalString(additional, "mount_point_type"),
+			Owner: FileOwner{
+				User:  optionalString(owner, "user"),
+				Group: optionalString(owner, "group"),
+				UID:   int(optionalInt(owner, "uid")),
+				GID:   int(optionalInt(owner, "gid")),
+			},
+			Time: FileTime{
+				Atime:  optionalTime(times, "atime"),
+				Mtime:  optionalTime(times, "mtime"),
+				Ctime:  optionalTime(times, "ctime"),
+				Crtime: optionalTime(times, "crtime"),
+			},
+			Perm: FilePerm{
+				Posix:     int(optionalInt(perm, "posix")),
+				IsACLMode: optionalBool(perm, "is_acl_mode"),
+				ACL: FileACL{
+					Append: optionalBool(acl, "append"),
+					Del:    optionalBool(acl, "del"),
+					Exec:   optionalBool(acl, "exec"),
+					Read:   optionalBool(acl, "read"),
+					Write:  optionalBool(acl, "write"),
+				},
+			},
+			Dimension: FileDimension{
+				Width:  int(optionalInt(dimension, "width")),
+				Height: int(optionalInt(dimension, "height")),
+			},
+		})
+	}
+	return fileInfos
+}
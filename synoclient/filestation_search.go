@@ -0,0 +1,177 @@
+package synoclient
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SearchOptions are the search criteria, empty fields are not used
+type SearchOptions struct {
+	Recursive bool
+	// Pattern is a case insensitive glob pattern of file names
+	Pattern   string
+	Extension string
+	// FileType file, dir or all
+	FileType  string
+	SizeFrom  int64
+	SizeTo    int64
+	MtimeFrom time.Time
+	MtimeTo   time.Time
+	Owner     string
+	Group     string
+	// Additional info of found files: real_path, size, owner, time, perm, type, mount_point_type
+	Additional []string
+}
+
+// SearchIterator streams results of a FileStation search task
+//
+//	it, err := c.Search([]string{"/video"}, SearchOptions{Pattern: "*.mkv", Recursive: true})
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.File().Path)
+//	}
+//	if it.Err() != nil { ... }
+type SearchIterator struct {
+	c          *Client
+	taskID     string
+	additional []string
+	offset     int
+	files      []FileInfo
+	current    FileInfo
+	done       bool
+	err        error
+}
+
+func (o SearchOptions) params() map[string]string {
+	params := map[string]string{
+		"recursive": strconv.FormatBool(o.Recursive),
+	}
+	if o.Pattern != "" {
+		params["pattern"] = o.Pattern
+	}
+	if o.Extension != "" {
+		params["extension"] = o.Extension
+	}
+	if o.FileType != "" {
+		params["filetype"] = o.FileType
+	}
+	if o.SizeFrom > 0 {
+		params["size_from"] = strconv.FormatInt(o.SizeFrom, 10)
+	}
+	if o.SizeTo > 0 {
+		params["size_to"] = strconv.FormatInt(o.SizeTo, 10)
+	}
+	if !o.MtimeFrom.IsZero() {
+		params["mtime_from"] = strconv.FormatInt(o.MtimeFrom.Unix(), 10)
+	}
+	if !o.MtimeTo.IsZero() {
+		params["mtime_to"] = strconv.FormatInt(o.MtimeTo.Unix(), 10)
+	}
+	if o.Owner != "" {
+		params["owner"] = o.Owner
+	}
+	if o.Group != "" {
+		params["group"] = o.Group
+	}
+	return params
+}
+
+// Search starts searching folders, the caller must close the iterator
+// to release the search task
+func (c *Client) Search(folderPaths []string, opts SearchOptions) (*SearchIterator, error) {
+	params := opts.params()
+	params["api"] = "SYNO.FileStation.Search"
+	params["version"] = "2"
+	params["method"] = "start"
+	params["folder_path"] = pathList(folderPaths)
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, fsError(resp, err)
+	}
+
+	taskID := c.GetData(resp).(map[string]interface{})["taskid"].(string)
+	return &SearchIterator{c: c, taskID: taskID, additional: opts.Additional}, nil
+}
+
+// Next advances to the next result, waiting for the search to find more
+func (it *SearchIterator) Next() bool {
+	for len(it.files) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		var finished bool
+		finished, it.err = it.list()
+		if it.err != nil {
+			return false
+		}
+		if len(it.files) == 0 {
+			if finished {
+				it.done = true
+				return false
+			}
+			time.Sleep(FileTaskPollInterval)
+		}
+	}
+
+	it.current = it.files[0]
+	it.files = it.files[1:]
+	return true
+}
+
+// list fetches results found since the last call
+func (it *SearchIterator) list() (bool, error) {
+	params := map[string]string{
+		"api":        "SYNO.FileStation.Search",
+		"version":    "2",
+		"method":     "list",
+		"taskid":     it.taskID,
+		"offset":     strconv.Itoa(it.offset),
+		"limit":      strconv.Itoa(defaultPageSize),
+		"additional": strings.Join(it.additional, ","),
+	}
+
+	resp, err := it.c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return false, fsError(resp, err)
+	}
+
+	data := it.c.GetData(resp).(map[string]interface{})
+	files, _ := data["files"].([]interface{})
+	it.files = mapFileInfo(files)
+	it.offset += len(it.files)
+
+	// results may still be paged after the search finished
+	finished := optionalBool(data, "finished") && it.offset >= int(optionalInt(data, "total"))
+	return finished, nil
+}
+
+// File returns the current result
+func (it *SearchIterator) File() FileInfo {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Close stops the search and releases its results
+func (it *SearchIterator) Close() error {
+	for _, method := range []string{"stop", "clean"} {
+		params := map[string]string{
+			"api":     "SYNO.FileStation.Search",
+			"version": "2",
+			"method":  method,
+			"taskid":  it.taskID,
+		}
+		resp, err := it.c.Get("webapi/entry.cgi", params)
+		if err != nil {
+			return fsError(resp, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package synoclient
+
+import (
+	"strconv"
+	"time"
+)
+
+var FsSharingErrors = map[int]string{
+	2000: "Sharing link does not exist",
+	2001: "Cannot generate sharing link because too many sharing links exist",
+	2002: "Failed to access sharing links",
+}
+
+// SharingLink is a public link to a file or folder
+type SharingLink struct {
+	ID          string `json:"id"`
+	URL         string `json:"url"`
+	Owner       string `json:"owner"`
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	IsFolder    bool   `json:"is_folder"`
+	HasPassword bool   `json:"has_password"`
+	// zero dates are not limited
+	DateExpired   time.Time `json:"date_expired"`
+	DateAvailable time.Time `json:"date_available"`
+	// Status valid, invalid, expired or broken
+	Status string `json:"status"`
+}
+
+// SharingOptions are optional settings of sharing links, zero values
+// are not set
+type SharingOptions struct {
+	Password      string
+	DateExpired   time.Time
+	DateAvailable time.Time
+}
+
+func (o SharingOptions) params() map[string]string {
+	params := map[string]string{}
+	if o.Password != "" {
+		params["password"] = o.Password
+	}
+	if !o.DateExpired.IsZero() {
+		params["date_expired"] = o.DateExpired.Format("2006-01-02")
+	}
+	if !o.DateAvailable.IsZero() {
+		params["date_available"] = o.DateAvailable.Format("2006-01-02")
+	}
+	return params
+}
+
+func (c *Client) sharing(method string, params map[string]string) (string, error) {
+	params["api"] = "SYNO.FileStation.Sharing"
+	params["version"] = "3"
+	params["method"] = method
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return "", fsError(resp, err)
+	}
+	return resp, nil
+}
+
+// CreateSharingLinks creates a sharing link for each path
+func (c *Client) CreateSharingLinks(paths []string, opts SharingOptions) ([]SharingLink, error) {
+	params := opts.params()
+	params["path"] = pathList(paths)
+
+	resp, err := c.sharing("create", params)
+	if err != nil {
+		return nil, err
+	}
+
+	links, _ := c.GetData(resp).(map[string]interface{})["links"].([]interface{})
+	return mapSharingLinks(links), nil
+}
+
+// ListSharingLinks returns sharing links of the user and their total number
+func (c *Client) ListSharingLinks(offset int, limit int) ([]SharingLink, int, error) {
+	params := map[string]string{
+		"offset": strconv.Itoa(offset),
+		"limit":  strconv.Itoa(limit),
+	}
+
+	resp, err := c.sharing("list", params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data := c.GetData(resp).(map[string]interface{})
+	links, _ := data["links"].([]interface{})
+	return mapSharingLinks(links), int(optionalInt(data, "total")), nil
+}
+
+// GetSharingLink returns a sharing link by id
+func (c *Client) GetSharingLink(id string) (SharingLink, error) {
+	resp, err := c.sharing("getinfo", map[string]string{"id": id})
+	if err != nil {
+		return SharingLink{}, err
+	}
+
+	return mapSharingLinks([]interface{}{c.GetData(resp)})[0], nil
+}
+
+// EditSharingLinks changes password and dates of sharing links
+func (c *Client) EditSharingLinks(ids []string, opts SharingOptions) error {
+	params := opts.params()
+	params["id"] = pathList(ids)
+
+	_, err := c.sharing("edit", params)
+	return err
+}
+
+// DeleteSharingLinks deletes sharing links by id
+func (c *Client) DeleteSharingLinks(ids []string) error {
+	_, err := c.sharing("delete", map[string]string{"id": pathList(ids)})
+	return err
+}
+
+// ClearInvalidSharingLinks deletes expired and broken sharing links
+func (c *Client) ClearInvalidSharingLinks() error {
+	_, err := c.sharing("clear_invalid", map[string]string{})
+	return err
+}
+
+func mapSharingLinks(links []interface{}) []SharingLink {
+	var sharingLinks []SharingLink
+	for _, link := range links {
+		l, _ := link.(map[string]interface{})
+		sharingLinks = append(sharingLinks, SharingLink{
+			ID:            optionalString(l, "id"),
+			URL:           optionalString(l, "url"),
+			Owner:         optionalString(l, "link_owner"),
+			Path:          optionalString(l, "path"),
+			Name:          optionalString(l, "name"),
+			IsFolder:      optionalBool(l, "isFolder"),
+			HasPassword:   optionalBool(l, "has_password"),
+			DateExpired:   parseSharingDate(optionalString(l, "date_expired")),
+			DateAvailable: parseSharingDate(optionalString(l, "date_available")),
+			Status:        optionalString(l, "status"),
+		})
+	}
+	return sharingLinks
+}
+
+// parseSharingDate parses dates like "2020-03-01 12:00:00", unlimited
+// dates are reported as "0"
+func parseSharingDate(date string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,149 @@
+package synoclient
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// FileTaskPollInterval is how often FileTask.Wait asks for the task status
+var FileTaskPollInterval = time.Second
+
+// FileTask is a FileStation background task, e.g. copy, move or delete
+type FileTask struct {
+	c       *Client
+	api     string
+	version string
+	TaskID  string
+}
+
+// FileTaskStatus is the progress of a FileTask. Fields not reported by
+// the task API are left empty.
+type FileTaskStatus struct {
+	Finished bool `json:"finished"`
+	// Progress between 0 and 1
+	Progress       float64 `json:"progress"`
+	ProcessedSize  int64   `json:"processed_size"`
+	ProcessedNum   int64   `json:"processed_num"`
+	Total          int64   `json:"total"`
+	Path           string  `json:"path"`
+	ProcessingPath string  `json:"processing_path"`
+	DestFolderPath string  `json:"dest_folder_path"`
+	DestFilePath   string  `json:"dest_file_path"`
+	// MD5 is the result of an MD5 task
+	MD5 string `json:"md5"`
+	// results of a DirSize task
+	NumDir    int64 `json:"num_dir"`
+	NumFile   int64 `json:"num_file"`
+	TotalSize int64 `json:"total_size"`
+}
+
+// startFileTask starts a background task and returns its handle
+func (c *Client) startFileTask(api string, version string, params map[string]string) (*FileTask, error) {
+	params["api"] = api
+	params["version"] = version
+	params["method"] = "start"
+
+	resp, err := c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, fsError(resp, err)
+	}
+
+	taskID := c.GetData(resp).(map[string]interface{})["taskid"].(string)
+	return &FileTask{c: c, api: api, version: version, TaskID: taskID}, nil
+}
+
+// Status returns the current status of the task. Failures of the task,
+// e.g. errors at the destination, are returned as error.
+func (t *FileTask) Status() (*FileTaskStatus, error) {
+	params := map[string]string{
+		"api":     t.api,
+		"version": t.version,
+		"method":  "status",
+		"taskid":  t.TaskID,
+	}
+
+	resp, err := t.c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return nil, fsError(resp, err)
+	}
+
+	data, _ := t.c.GetData(resp).(map[string]interface{})
+	return mapFileTaskStatus(data), nil
+}
+
+// AttachFileTask returns the handle of an already running task, e.g. one
+// found by ListBackgroundTasks or started by another client
+func (c *Client) AttachFileTask(api string, version string, taskID string) *FileTask {
+	return &FileTask{c: c, api: api, version: version, TaskID: taskID}
+}
+
+// Wait blocks until the task is finished
+func (t *FileTask) Wait(ctx context.Context) (*FileTaskStatus, error) {
+	return t.Watch(ctx, nil)
+}
+
+// Watch blocks until the task is finished and passes every status to fn
+func (t *FileTask) Watch(ctx context.Context, fn func(*FileTaskStatus)) (*FileTaskStatus, error) {
+	ticker := time.NewTicker(FileTaskPollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := t.Status()
+		if err != nil {
+			return nil, err
+		}
+		if fn != nil {
+			fn(status)
+		}
+		if status.Finished {
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop cancels the task
+func (t *FileTask) Stop() error {
+	params := map[string]string{
+		"api":     t.api,
+		"version": t.version,
+		"method":  "stop",
+		"taskid":  t.TaskID,
+	}
+
+	resp, err := t.c.Get("webapi/entry.cgi", params)
+	if err != nil {
+		return fsError(resp, err)
+	}
+	return nil
+}
+
+func mapFileTaskStatus(data map[string]interface{}) *FileTaskStatus {
+	progress, _ := data["progress"].(float64)
+	return &FileTaskStatus{
+		Finished:       optionalBool(data, "finished"),
+		Progress:       progress,
+		ProcessedSize:  optionalInt(data, "processed_size"),
+		ProcessedNum:   optionalInt(data, "processed_num"),
+		Total:          optionalInt(data, "total"),
+		Path:           optionalString(data, "path"),
+		ProcessingPath: optionalString(data, "processing_path"),
+		DestFolderPath: optionalString(data, "dest_folder_path"),
+		DestFilePath:   optionalString(data, "dest_file_path"),
+		MD5:            optionalString(data, "md5"),
+		NumDir:         optionalInt(data, "num_dir"),
+		NumFile:        optionalInt(data, "num_file"),
+		TotalSize:      optionalInt(data, "total_size"),
+	}
+}
+
+// pathList encodes paths as a FileStation list parameter
+func pathList(paths []string) string {
+	list, _ := json.Marshal(paths)
+	return string(list)
+}
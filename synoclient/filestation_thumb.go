@@ -0,0 +1,91 @@
+package synoclient
+
+import (
+	"image"
+	"io"
+	"strconv"
+	"strings"
+
+	// decoders for ImageConfig
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ThumbSize is the size of a thumbnail returned by Thumb
+type ThumbSize string
+
+const (
+	ThumbSmall    ThumbSize = "small"
+	ThumbMedium   ThumbSize = "medium"
+	ThumbLarge    ThumbSize = "large"
+	ThumbOriginal ThumbSize = "original"
+)
+
+// ThumbOptions of Thumb
+type ThumbOptions struct {
+	// Size defaults to small
+	Size ThumbSize
+	// Rotate 0-4 rotates the thumbnail by 0, 90, 180, 270 or 360 degrees
+	Rotate int
+}
+
+// Thumbnail is an image streamed from FileStation. The caller must close it.
+type Thumbnail struct {
+	io.ReadCloser
+	// ContentType of the image, e.g. image/jpeg
+	ContentType string
+}
+
+// Thumb returns the thumbnail of an image or video file
+func (c *Client) Thumb(filePath string, opts ThumbOptions) (*Thumbnail, error) {
+	params := map[string]string{
+		"api":     "SYNO.FileStation.Thumb",
+		"version": "2",
+		"method":  "get",
+		"path":    filePath,
+		"size":    string(ThumbSmall),
+		"rotate":  strconv.Itoa(opts.Rotate),
+	}
+	if opts.Size != "" {
+		params["size"] = string(opts.Size)
+	}
+
+	// failures are reported as JSON instead of the image
+	resp, body, err := c.GetStream("webapi/entry.cgi", params, nil)
+	if err != nil {
+		return nil, fsError(body, err)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	return &Thumbnail{ReadCloser: resp.Body, ContentType: contentType}, nil
+}
+
+// ImageConfig returns the dimensions and format (e.g. jpg or mp4) of an
+// image or video as indexed by the NAS. JPEG, PNG and GIF images which are
+// not indexed are measured by downloading the image header.
+func (c *Client) ImageConfig(filePath string) (image.Config, string, error) {
+	files, err := c.GetFileInfo([]string{filePath}, []string{"type", "dimension"})
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	if len(files) == 0 {
+		return image.Config{}, "", &ApplicationError{code: 408, reason: fsErrorCodes[408]}
+	}
+	info := files[0]
+	if info.Dimension.Width > 0 && info.Dimension.Height > 0 {
+		config := image.Config{Width: info.Dimension.Width, Height: info.Dimension.Height}
+		return config, strings.ToLower(info.Type), nil
+	}
+
+	download, err := c.Download(filePath, 0)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	defer download.Close()
+
+	config, format, err := image.DecodeConfig(download)
+	if err != nil {
+		return image.Config{}, "", &GenericError{desc: "No dimensions known of " + filePath}
+	}
+	return config, format, nil
+}
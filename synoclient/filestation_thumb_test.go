@@ -0,0 +1,58 @@
+package synoclient
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestImageConfig(t *testing.T) {
+	var picture bytes.Buffer
+	png.Encode(&picture, image.NewGray(image.Rect(0, 0, 3, 2)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Form.Get("api") + "." + r.Form.Get("method") {
+		case "SYNO.FileStation.List.getinfo":
+			w.Header().Set("Content-Type", "application/json")
+			if r.Form.Get("path") == `["/video/movie.mkv"]` {
+				w.Write([]byte(`{"success":true,"data":{"files":[{"path":"/video/movie.mkv","name":"movie.mkv",` +
+					`"additional":{"type":"MKV","dimension":{"width":1920,"height":1080}}}]}}`))
+			} else {
+				w.Write([]byte(`{"success":true,"data":{"files":[{"path":"/photo/a.png","name":"a.png",` +
+					`"additional":{"type":"PNG"}}]}}`))
+			}
+		case "SYNO.FileStation.Download.download":
+			w.Header().Set("Content-Disposition", `attachment; filename="a.png"`)
+			w.Write(picture.Bytes())
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	c := &Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	tests := []struct {
+		path   string
+		format string
+		width  int
+		height int
+	}{
+		{path: "/video/movie.mkv", format: "mkv", width: 1920, height: 1080},
+		{path: "/photo/a.png", format: "png", width: 3, height: 2},
+	}
+	for _, tt := range tests {
+		config, format, err := c.ImageConfig(tt.path)
+		if err != nil {
+			t.Errorf("ImageConfig(%v): %v", tt.path, err)
+			continue
+		}
+		if format != tt.format || config.Width != tt.width || config.Height != tt.height {
+			t.Errorf("ImageConfig(%v) = %v %vx%v, want %v %vx%v", tt.path,
+				format, config.Width, config.Height, tt.format, tt.width, tt.height)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package synoclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UploadOptions are optional parameters of Upload
+type UploadOptions struct {
+	// CreateParents creates missing parent folders of the destination
+	CreateParents bool
+	Conflict      ConflictMode
+	// times of the uploaded file, zero times are set by the NAS
+	Mtime  time.Time
+	Crtime time.Time
+	Atime  time.Time
+	// Progress is called with the number of bytes uploaded so far
+	Progress func(uploaded int64)
+}
+
+// progressReader reports the number of bytes read
+type progressReader struct {
+	r        io.Reader
+	read     int64
+	progress func(int64)
+}
+
+// Seek rewinds the content for uploading it again
+func (p *progressReader) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := p.r.(io.Seeker)
+	if !ok {
+		return 0, &GenericError{desc: "Content cannot be rewound"}
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	n, err := seeker.Seek(offset, whence)
+	if err == nil {
+		p.read += n - current
+	}
+	return n, err
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if n > 0 {
+		p.progress(p.read)
+	}
+	return n, err
+}
+
+// Upload streams size bytes of content as file name into destinationDir
+func (c *Client) Upload(destinationDir string, name string, content io.Reader, size int64, opts UploadOptions) error {
+	params := map[string]string{
+		"api":            "SYNO.FileStation.Upload",
+		"version":        "2",
+		"method":         "upload",
+		"path":           destinationDir,
+		"create_parents": strconv.FormatBool(opts.CreateParents),
+	}
+	switch opts.Conflict {
+	case ConflictOverwrite:
+		params["overwrite"] = "true"
+	case ConflictSkip:
+		params["overwrite"] = "false"
+	}
+	// times are in milliseconds
+	for param, t := range map[string]time.Time{"mtime": opts.Mtime, "crtime": opts.Crtime, "atime": opts.Atime} {
+		if !t.IsZero() {
+			params[param] = strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+		}
+	}
+
+	if opts.Progress != nil {
+		content = &progressReader{r: content, progress: opts.Progress}
+	}
+
+	resp, err := c.PostMultipart("webapi/entry.cgi", params, "file", name, content, size)
+	if err != nil {
+		return fsError(resp, err)
+	}
+	return nil
+}
+
+// Download is the content of a downloaded file
+type Download struct {
+	io.ReadCloser
+	// Offset of the first byte, 0 if the requested offset was not honored
+	Offset int64
+	// Size of the whole file, -1 if unknown
+	Size int64
+	// Name suggested by the server, e.g. archive.zip for folders
+	Name string
+}
+
+// ErrRangeNotSatisfiable is returned by Download for offsets at or beyond
+// the end of the file
+var ErrRangeNotSatisfiable = errors.New("Requested range not satisfiable")
+
+// Download returns the content of a file starting at offset, folders are
+// returned as zip archive. The caller must close the returned Download.
+func (c *Client) Download(filePath string, offset int64) (*Download, error) {
+	return c.download(filePath, offset)
+}
+
+// DownloadZip returns files and folders packed in a zip archive
+func (c *Client) DownloadZip(paths []string) (*Download, error) {
+	return c.download(pathList(paths), 0)
+}
+
+func (c *Client) download(path string, offset int64) (*Download, error) {
+	params := map[string]string{
+		"api":     "SYNO.FileStation.Download",
+		"version": "2",
+		"method":  "download",
+		"path":    path,
+		"mode":    "download",
+	}
+
+	header := http.Header{}
+	if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, body, err := c.GetStream("webapi/entry.cgi", params, header)
+	if err != nil {
+		var generic *GenericError
+		if errors.As(err, &generic) && generic.status == http.StatusRequestedRangeNotSatisfiable {
+			return nil, ErrRangeNotSatisfiable
+		}
+		return nil, fsError(body, err)
+	}
+
+	download := &Download{ReadCloser: resp.Body, Size: -1}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		download.Name = params["filename"]
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		// Content-Range: bytes 100-199/200
+		var start, end, size int64
+		_, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size)
+		if err != nil || start != offset {
+			// the content cannot be placed, start over with the whole file
+			resp.Body.Close()
+			if offset == 0 {
+				return nil, &GenericError{desc: "Invalid Content-Range " + resp.Header.Get("Content-Range")}
+			}
+			return c.download(path, 0)
+		}
+		download.Offset = start
+		download.Size = size
+	} else if resp.ContentLength >= 0 {
+		download.Size = resp.ContentLength
+	}
+	return download, nil
+}
@@ -0,0 +1,75 @@
+package synoclient
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDownloadRange(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentRange string
+		wantOffset   int64
+		wantContent  string
+		wantRequests int
+	}{
+		{name: "honored", contentRange: "bytes 2-4/5", wantOffset: 2, wantContent: "llo", wantRequests: 1},
+		{name: "missing Content-Range", wantContent: "hello", wantRequests: 2},
+		{name: "invalid Content-Range", contentRange: "bytes */5", wantContent: "hello", wantRequests: 2},
+		{name: "other start", contentRange: "bytes 1-4/5", wantContent: "hello", wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.Header.Get("Range") == "" {
+					w.Write([]byte("hello"))
+					return
+				}
+				if tt.contentRange != "" {
+					w.Header().Set("Content-Range", tt.contentRange)
+				}
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write([]byte("llo"))
+			}))
+			defer server.Close()
+			u, _ := url.Parse(server.URL)
+			c := &Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+			download, err := c.Download("/video/a.txt", 2)
+			if err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			defer download.Close()
+			content, err := ioutil.ReadAll(download)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if download.Offset != tt.wantOffset || string(content) != tt.wantContent {
+				t.Errorf("Download = offset %v %q, want offset %v %q", download.Offset, content, tt.wantOffset, tt.wantContent)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDownloadRangeNotSatisfiable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", "bytes */5")
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	c := &Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	if _, err := c.Download("/video/a.txt", 5); !errors.Is(err, ErrRangeNotSatisfiable) {
+		t.Errorf("Download = %v, want ErrRangeNotSatisfiable", err)
+	}
+}
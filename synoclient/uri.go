@@ -0,0 +1,180 @@
+package synoclient
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// TaskURI is a parsed download task URI
+type TaskURI struct {
+	Raw    string `json:"raw"`
+	Scheme string `json:"scheme"`
+	// InfoHash is the lower case hex BitTorrent info-hash of magnet links,
+	// the v2 multihash if there is no v1 hash, or the MD4 file hash of
+	// ed2k links
+	InfoHash    string   `json:"info_hash"`
+	DisplayName string   `json:"display_name"`
+	Size        int64    `json:"size"`
+	Trackers    []string `json:"trackers"`
+}
+
+// ParseTaskURI validates magnet, http(s), ftp(s) and ed2k URIs
+func ParseTaskURI(raw string) (*TaskURI, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("Empty URI")
+	}
+
+	i := strings.Index(raw, ":")
+	if i <= 0 {
+		return nil, fmt.Errorf("Unsupported URI %v", raw)
+	}
+	scheme := strings.ToLower(raw[:i])
+
+	switch scheme {
+	case "magnet":
+		return parseMagnet(raw)
+	case "ed2k":
+		return parseEd2k(raw)
+	case "http", "https", "ftp", "ftps":
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid URL %v: %v", raw, err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("Invalid URL %v: missing host", raw)
+		}
+		return &TaskURI{Raw: raw, Scheme: scheme, DisplayName: u.Path}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported URI %v", raw)
+}
+
+// Key identifies the download regardless of URI formatting
+func (u *TaskURI) Key() string {
+	if u.InfoHash != "" {
+		return u.Scheme + ":" + u.InfoHash
+	}
+	return u.Raw
+}
+
+func parseMagnet(raw string) (*TaskURI, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid magnet link %v: %v", raw, err)
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid magnet link %v: %v", raw, err)
+	}
+
+	uri := &TaskURI{
+		Raw:         raw,
+		Scheme:      "magnet",
+		DisplayName: query.Get("dn"),
+		Trackers:    query["tr"],
+	}
+
+	for _, xt := range query["xt"] {
+		if strings.HasPrefix(xt, "urn:btmh:") {
+			// BitTorrent v2 multihash, 1220 is sha2-256 of 32 bytes
+			hash := strings.TrimPrefix(xt, "urn:btmh:")
+			if _, err := hex.DecodeString(hash); err != nil || len(hash) != 68 || !strings.HasPrefix(hash, "1220") {
+				return nil, fmt.Errorf("Invalid magnet link %v: malformed info-hash", raw)
+			}
+			if uri.InfoHash == "" {
+				uri.InfoHash = strings.ToLower(hash)
+			}
+			continue
+		}
+		if !strings.HasPrefix(xt, "urn:btih:") {
+			continue
+		}
+		hash := strings.TrimPrefix(xt, "urn:btih:")
+		switch len(hash) {
+		case 40:
+			if _, err := hex.DecodeString(hash); err != nil {
+				return nil, fmt.Errorf("Invalid magnet link %v: malformed info-hash", raw)
+			}
+			uri.InfoHash = strings.ToLower(hash)
+		case 32:
+			b, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+			if err != nil {
+				return nil, fmt.Errorf("Invalid magnet link %v: malformed info-hash", raw)
+			}
+			uri.InfoHash = hex.EncodeToString(b)
+		default:
+			return nil, fmt.Errorf("Invalid magnet link %v: malformed info-hash", raw)
+		}
+	}
+
+	if uri.InfoHash == "" {
+		return nil, fmt.Errorf("Invalid magnet link %v: missing info-hash", raw)
+	}
+	return uri, nil
+}
+
+// ed2k://|file|<name>|<size>|<hash>|/
+func parseEd2k(raw string) (*TaskURI, error) {
+	parts := strings.Split(strings.TrimPrefix(raw[strings.Index(raw, ":")+1:], "//"), "|")
+	if len(parts) < 6 || parts[1] != "file" {
+		return nil, fmt.Errorf("Invalid ed2k link %v", raw)
+	}
+
+	name, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ed2k link %v: %v", raw, err)
+	}
+	size, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ed2k link %v: malformed size", raw)
+	}
+	if _, err := hex.DecodeString(parts[4]); err != nil || len(parts[4]) != 32 {
+		return nil, fmt.Errorf("Invalid ed2k link %v: malformed hash", raw)
+	}
+
+	return &TaskURI{
+		Raw:         raw,
+		Scheme:      "ed2k",
+		InfoHash:    strings.ToLower(parts[4]),
+		DisplayName: name,
+		Size:        size,
+	}, nil
+}
+
+// TaskIndex keeps track of task URIs to detect duplicates. It is safe
+// for concurrent use by multiple workers.
+type TaskIndex struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// NewTaskIndex indexes URIs of existing download tasks
+func NewTaskIndex(tasks []DownloadStationTask) *TaskIndex {
+	index := &TaskIndex{keys: make(map[string]bool)}
+	for _, task := range tasks {
+		raw := task.AdditinalTaskInfo.TaskDetail.Uri
+		if uri, err := ParseTaskURI(raw); err == nil {
+			index.keys[uri.Key()] = true
+		} else if raw != "" {
+			index.keys[raw] = true
+		}
+	}
+	return index
+}
+
+// Add records the URI and reports whether it was not indexed yet
+func (i *TaskIndex) Add(uri *TaskURI) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.keys[uri.Key()] {
+		return false
+	}
+	i.keys[uri.Key()] = true
+	return true
+}
@@ -0,0 +1,69 @@
+package synoclient
+
+import "testing"
+
+func TestParseTaskURI(t *testing.T) {
+	const btih = "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+	const btmh = "1220caf1e1c30e81cb361b9ee167c4aa64228a7fa4fa9f6105232b28ad099f3a302e"
+
+	tests := []struct {
+		name     string
+		raw      string
+		wantErr  bool
+		scheme   string
+		infoHash string
+		display  string
+	}{
+		{name: "empty", raw: "", wantErr: true},
+		{name: "blank", raw: "   ", wantErr: true},
+		{name: "bare ed2k scheme", raw: "ed2k", wantErr: true},
+		{name: "bare magnet scheme", raw: "magnet", wantErr: true},
+		{name: "ed2k without link", raw: "ed2k:", wantErr: true},
+		{name: "leading colon", raw: ":foo", wantErr: true},
+		{name: "unsupported scheme", raw: "gopher://example.com/", wantErr: true},
+		{name: "http", raw: "http://example.com/file.iso", scheme: "http", display: "/file.iso"},
+		{name: "upper case scheme", raw: "HTTPS://example.com/a", scheme: "https", display: "/a"},
+		{name: "http without host", raw: "http:///file.iso", wantErr: true},
+		{name: "magnet hex", raw: "magnet:?xt=urn:btih:" + btih + "&dn=Name", scheme: "magnet", infoHash: btih, display: "Name"},
+		{name: "magnet upper hex", raw: "magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A", scheme: "magnet", infoHash: btih},
+		{name: "magnet base32", raw: "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK", scheme: "magnet", infoHash: btih},
+		{name: "magnet without hash", raw: "magnet:?dn=Name", wantErr: true},
+		{name: "magnet malformed hash", raw: "magnet:?xt=urn:btih:1234", wantErr: true},
+		{name: "magnet btmh", raw: "magnet:?xt=urn:btmh:" + btmh, scheme: "magnet", infoHash: btmh},
+		{name: "magnet malformed btmh", raw: "magnet:?xt=urn:btmh:1220abc", wantErr: true},
+		{name: "magnet hybrid prefers btih", raw: "magnet:?xt=urn:btmh:" + btmh + "&xt=urn:btih:" + btih, scheme: "magnet", infoHash: btih},
+		{name: "ed2k", raw: "ed2k://|file|My%20File.iso|1024|31D6CFE0D16AE931B73C59D7E0C089C0|/", scheme: "ed2k", infoHash: "31d6cfe0d16ae931b73c59d7e0c089c0", display: "My File.iso"},
+		{name: "ed2k malformed size", raw: "ed2k://|file|a|x|31D6CFE0D16AE931B73C59D7E0C089C0|/", wantErr: true},
+		{name: "ed2k malformed hash", raw: "ed2k://|file|a|1|31D6|/", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uri, err := ParseTaskURI(test.raw)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTaskURI(%q) = %+v, want error", test.raw, uri)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaskURI(%q): %v", test.raw, err)
+			}
+			if uri.Scheme != test.scheme || uri.InfoHash != test.infoHash || uri.DisplayName != test.display {
+				t.Errorf("ParseTaskURI(%q) = %+v, want scheme %q, info-hash %q, name %q", test.raw, uri, test.scheme, test.infoHash, test.display)
+			}
+		})
+	}
+}
+
+func TestTaskIndexDuplicates(t *testing.T) {
+	index := NewTaskIndex(nil)
+	first, _ := ParseTaskURI("magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a&dn=a")
+	second, _ := ParseTaskURI("magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK&dn=b")
+	if !index.Add(first) {
+		t.Fatal("first URI reported as duplicate")
+	}
+	if index.Add(second) {
+		t.Error("same info-hash in base32 not detected as duplicate")
+	}
+}
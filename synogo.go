@@ -9,6 +9,7 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -16,17 +17,38 @@ import (
 	"sync"
 
 	"github.com/macpoint/synogo/synoclient"
-	"github.com/olekukonko/tablewriter"
 )
 
 const version = 1.1
 
 func main() {
+	run()
+	os.Exit(exitStatus)
+}
+
+// legacyFlags are the single letter flags of synogo 1.0, each is an alias
+// of a task subcommand
+var legacyFlags = map[string]string{
+	"f": "add",
+	"u": "add",
+	"l": "list",
+	"d": "rm",
+	"p": "pause",
+	"r": "resume",
+	"m": "mv",
+	"i": "info",
+	"c": "clear",
+}
 
+func run() {
 	config, err := synoclient.LoadJsonConfiguration(filepath.Join(os.Getenv("HOME"), ".synogo.json"))
 	if err != nil {
-		fmt.Println(err)
-		return
+		// help and completion scripts work without configuration
+		if len(os.Args) < 2 || (os.Args[1] != "help" && os.Args[1] != "completion") {
+			printError(err)
+			return
+		}
+		config = &synoclient.Config{}
 	}
 
 	client := &synoclient.Client{
@@ -38,128 +60,186 @@ func main() {
 		Timeout:  config.Timeout,
 	}
 
-	file := flag.String("f", "", "Create download task from file")
-	url := flag.String("u", "", "Create download task from url")
-	list := flag.Bool("l", false, "List existing download tasks")
-	delete := flag.String("d", "", "Delete tasks ids separated by comma")
-	pause := flag.String("p", "", "Pause tasks ids separated by comma")
-	resume := flag.String("r", "", "Resume tasks ids separated by comma")
-	move := flag.String("m", "", "Move downloaded file to destination")
-	info := flag.String("i", "", "Display download task info")
-	clear := flag.Bool("c", false, "Clear all finished download tasks")
-
-	flag.Parse()
-	if *file != "" {
-		createDownloadTaskFromFile(client, *file)
-		return
-	}
-	if *url != "" {
-		createDownloadTaskfromURL(client, *url)
-		return
-	}
-
-	if *list {
-		getDownloadTasks(client)
-		return
-	}
-
-	if *delete != "" {
-		deleteDownloadTasks(client, *delete)
+	flag.String("f", "", "Create download task from file (task add -f)")
+	flag.String("u", "", "Create download task from url (task add)")
+	flag.Bool("l", false, "List existing download tasks (task list)")
+	flag.String("d", "", "Delete tasks ids separated by comma (task rm)")
+	flag.String("p", "", "Pause tasks ids separated by comma (task pause)")
+	flag.String("r", "", "Resume tasks ids separated by comma (task resume)")
+	flag.String("m", "", "Move downloaded file to destination, a full path or favorite name (task mv)")
+	flag.String("i", "", "Display download task info (task info)")
+	flag.Bool("c", false, "Clear all finished download tasks (task clear)")
+	skip := flag.Bool("s", false, "Skip URIs of existing download tasks (with -f or -u)")
+	flag.String("output", outputFormat, "Output format of every command: "+strings.Join(outputFormats, ", "))
+	flag.String("template", "", "Go template applied to each result of every command, e.g. '{{.ID}} {{.Title}}'")
+	flag.Usage = printUsage
+
+	// completed words may be incomplete output flags
+	if len(os.Args) > 1 && os.Args[1] == "__complete" {
+		completeArgs(client, os.Args[2:])
 		return
 	}
 
-	if *pause != "" {
-		pauseDownloadTasks(client, *pause)
-		return
-	}
-
-	if *resume != "" {
-		resumeDownloadTasks(client, *resume)
+	args, err := parseOutputFlags(os.Args[1:])
+	if err != nil {
+		printError(err)
+		setExitStatus(2)
 		return
 	}
+	synoclient.Output = messageOutput()
 
-	if *move != "" {
-
-		if len(flag.Args()) != 1 {
-			printUsage()
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, ok := commands[args[0]]
+		if !ok {
+			printErrorf("Unknown command %v, run '%v help' for usage\n", args[0], os.Args[0])
+			setExitStatus(2)
 			return
 		}
-		moveDownloadedFile(client, *move, flag.Args()[0])
+		runCommand(client, args[0], cmd, args[1:])
 		return
 	}
 
-	if *info != "" {
-		getDownloadTaskInfo(client, *info)
-		return
-	}
-
-	if *clear {
-		clearFinishedDownloadTasks(client)
+	flag.CommandLine.Parse(args)
+	var legacy []*flag.Flag
+	flag.Visit(func(f *flag.Flag) {
+		if _, ok := legacyFlags[f.Name]; ok {
+			legacy = append(legacy, f)
+		}
+	})
+	if len(legacy) != 1 {
+		if len(legacy) > 1 {
+			printErrorf("Only one of -f, -u, -l, -d, -p, -r, -m, -i and -c can be used\n")
+		} else {
+			printUsage()
+		}
+		setExitStatus(2)
 		return
 	}
 
-	printUsage()
-
+	// translate the flag to its task subcommand, e.g. -p 1,2 to task pause 1 2
+	name, value := legacy[0].Name, legacy[0].Value.String()
+	taskArgs := []string{legacyFlags[name]}
+	switch name {
+	case "f":
+		taskArgs = append(taskArgs, "-f", value)
+	case "u", "i":
+		taskArgs = append(taskArgs, value)
+	case "d", "p", "r":
+		taskArgs = append(taskArgs, strings.Split(value, ",")...)
+	case "m":
+		taskArgs = append(taskArgs, value)
+		taskArgs = append(taskArgs, flag.Args()...)
+	}
+	if *skip && (name == "f" || name == "u") {
+		taskArgs = append(taskArgs, "-s")
+	}
+	runCommand(client, "task", commands["task"], taskArgs)
 }
 
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s [--output format] [--template template] <command> [arguments]\n\n", os.Args[0])
 	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr)
+	printCommands("", commands)
 }
 
 func moveDownloadedFile(client *synoclient.Client, taskID string, destination string) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	task, err := client.GetDownloadStationTask(taskID)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	if task.Status != "finished" {
-		fmt.Printf("File %v cannot be moved. It has not beed downloaded yet.\n", task.Title)
+		printErrorf("File %v cannot be moved. It has not beed downloaded yet.\n", task.Title)
 		return
 	}
 
-	fileToMove := "/" + filepath.Join(task.AdditinalTaskInfo.TaskDetail.Destination, task.Title)
-	desiredFileName := filepath.Base(destination)
-	renamedFile, err := client.RenameFile(fileToMove, desiredFileName)
+	destination, err = resolveFavorite(client, destination, task.Title)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
+	result := taskResult{ID: task.ID, Action: "moved to " + destination}
+	if err := relocateTask(client, task, destination); err != nil {
+		printError(err)
+		result.Error = err.Error()
+	} else {
+		printMessage("File moved.\n")
+	}
+	client.Logout()
+	outputTaskResults([]taskResult{result})
+}
+
+// relocateTask renames the downloaded file of a finished task and moves it
+// to the destination directory
+func relocateTask(client *synoclient.Client, task synoclient.DownloadStationTask, destination string) error {
 	destinationDir := filepath.Dir(destination)
-	err = client.MoveFile(renamedFile, destinationDir)
-	if err != nil {
-		fmt.Println(err)
-		return
+	if _, err := client.MkdirAll(destinationDir); err != nil {
+		return err
 	}
 
-	fmt.Println("File moved.")
-	client.Logout()
+	fileToMove := "/" + filepath.Join(task.AdditinalTaskInfo.TaskDetail.Destination, task.Title)
+	desiredFileName := filepath.Base(destination)
+	if desiredFileName == task.Title {
+		return client.MoveFile(fileToMove, destinationDir)
+	}
+
+	renamedFile, err := client.RenameFile(fileToMove, desiredFileName)
+	if err != nil {
+		return err
+	}
+	if err := client.MoveFile(renamedFile, destinationDir); err != nil {
+		// the task keeps pointing to its original file name
+		if _, renameErr := client.RenameFile(renamedFile, task.Title); renameErr != nil {
+			return fmt.Errorf("%v, %v was not renamed back: %v", err, renamedFile, renameErr)
+		}
+		return err
+	}
+	return nil
 }
 
 func deleteDownloadTasks(client *synoclient.Client, tasks string) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	resp, err := client.DeleteDownloadStationTasks(tasks)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	// 'delete' response always returns success: true
+	outputTaskResults(printTaskResults(client, resp, "delete", "deleted"))
+
+	// Logout
+	client.Logout()
+}
+
+// taskResult is the outcome of an operation on a single task
+type taskResult struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// printTaskResults prints results of a per task operation as messages and
+// returns them. verb is used for failures, e.g. Could not delete task id X,
+// action for successes, e.g. Task X deleted.
+func printTaskResults(client *synoclient.Client, resp string, verb string, action string) []taskResult {
 	// response is an array of response objects with following parameters
 	/*
 		"data": [
@@ -173,43 +253,61 @@ func deleteDownloadTasks(client *synoclient.Client, tasks string) {
 			}
 		],
 	*/
+	var taskResults []taskResult
 	results := client.GetData(resp).([]interface{})
 	for _, result := range results {
 		r := result.(map[string]interface{})
+		id := fmt.Sprint(r["id"])
 		if int(r["error"].(float64)) > 0 {
-			fmt.Printf("Could not delete task id %v (%v).\n", r["id"], synoclient.DsSynoErrors[int(r["error"].(float64))])
+			reason := synoclient.DsSynoErrors[int(r["error"].(float64))]
+			printErrorf("Could not %v task id %v (%v).\n", verb, id, reason)
+			taskResults = append(taskResults, taskResult{ID: id, Action: action, Error: reason})
 		} else {
-			fmt.Printf("Task %v deleted.\n", r["id"])
+			printMessage("Task %v %v.\n", id, action)
+			taskResults = append(taskResults, taskResult{ID: id, Action: action})
 		}
 	}
+	return taskResults
+}
 
-	// Logout
-	client.Logout()
+// outputTaskResults writes task results in structured formats, tables are
+// already printed as messages
+func outputTaskResults(results []taskResult) {
+	var rows [][]string
+	for _, result := range results {
+		rows = append(rows, []string{result.ID, result.Action, result.Error})
+	}
+	printOutput(results, []string{"Id", "Action", "Error"}, rows, func() {})
+}
+
+// idResults are the results of a single operation on several tasks
+func idResults(ids []string, action string, err error) []taskResult {
+	var results []taskResult
+	for _, id := range ids {
+		result := taskResult{ID: id, Action: action}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
 }
 
 func resumeDownloadTasks(client *synoclient.Client, tasks string) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	resp, err := client.ResumeDownloadStationTasks(tasks)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
-	results := client.GetData(resp).([]interface{})
-	for _, result := range results {
-		r := result.(map[string]interface{})
-		if int(r["error"].(float64)) > 0 {
-			fmt.Printf("Could not resume task id %v (%v).\n", r["id"], synoclient.DsSynoErrors[int(r["error"].(float64))])
-		} else {
-			fmt.Printf("Task %v resumed.\n", r["id"])
-		}
-	}
+	outputTaskResults(printTaskResults(client, resp, "resume", "resumed"))
 
 	// Logout
 	client.Logout()
@@ -219,45 +317,59 @@ func pauseDownloadTasks(client *synoclient.Client, tasks string) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	resp, err := client.PauseDownloadStationTasks(tasks)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
-	results := client.GetData(resp).([]interface{})
-	for _, result := range results {
-		r := result.(map[string]interface{})
-		if int(r["error"].(float64)) > 0 {
-			fmt.Printf("Could not pause task id %v (%v).\n", r["id"], synoclient.DsSynoErrors[int(r["error"].(float64))])
-		} else {
-			fmt.Printf("Task %v paused.\n", r["id"])
-		}
-	}
+	outputTaskResults(printTaskResults(client, resp, "pause", "paused"))
 
 	// Logout
 	client.Logout()
 }
 
-func createDownloadTaskFromFile(client *synoclient.Client, filepath string) {
-	// Login
-	_, err := client.Login()
+// newTaskIndex returns an index of existing tasks if duplicates should be skipped
+func newTaskIndex(client *synoclient.Client, skipDuplicates bool) (*synoclient.TaskIndex, error) {
+	if !skipDuplicates {
+		return nil, nil
+	}
+	tasks, err := client.ListDownloadStationTasks()
 	if err != nil {
-		fmt.Println(err)
-		return
+		return nil, err
 	}
+	return synoclient.NewTaskIndex(tasks), nil
+}
 
+func createDownloadTaskFromFile(client *synoclient.Client, filepath string, skipDuplicates bool) {
 	file, err := os.Open(filepath)
 	if err != nil {
-		fmt.Printf("Could not open file %v\n", filepath)
-		os.Exit(1)
+		printErrorf("Could not open file %v\n", filepath)
+		return
 	}
 	defer file.Close()
 
+	var uris []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		uris = append(uris, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		printError(err)
+		return
+	}
+
+	createDownloadTasksFromURIs(client, uris, skipDuplicates)
+}
+
+// queueDownloadTasks creates a download task from each line of r using
+// a pool of workers and passes every failure with its line, counted from 0,
+// to handleError
+func queueDownloadTasks(client *synoclient.Client, r io.Reader, index *synoclient.TaskIndex, handleError func(line int, data *synoclient.TaskAddError)) error {
 	// create sync & queue & add workers to sync group
 	var processWg sync.WaitGroup
 	var errorWg sync.WaitGroup
@@ -265,25 +377,35 @@ func createDownloadTaskFromFile(client *synoclient.Client, filepath string) {
 
 	processWg.Add(noOfWorkers)
 	errorWg.Add(1)
-	fileProcessQueue := make(chan string, noOfWorkers)
-	errorQueue := make(chan *synoclient.TaskAddError)
+	fileProcessQueue := make(chan taskLine, noOfWorkers)
+	errorQueue := make(chan taskLineError)
 
 	// create workers
 	for gr := 1; gr <= noOfWorkers; gr++ {
-		go client.CreateDownloadStationTask(fileProcessQueue, errorQueue, &processWg)
+		go func() {
+			defer processWg.Done()
+			for task := range fileProcessQueue {
+				if err := client.AddDownloadStationTask(index, task.uri); err != nil {
+					errorQueue <- taskLineError{line: task.line, data: &synoclient.TaskAddError{Name: task.uri, Err: err}}
+				}
+			}
+		}()
 	}
 
 	// read the error queue
-	go readErrors(errorQueue, &errorWg)
-
-	// fill the queue with each line of the file
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		fileProcessQueue <- scanner.Text()
-	}
+	go func() {
+		defer errorWg.Done()
+		for err := range errorQueue {
+			handleError(err.line, err.data)
+		}
+	}()
 
-	if err := scanner.Err(); err != nil {
-		fmt.Println(err)
+	// fill the queue with each line of the file, blank lines are skipped
+	scanner := bufio.NewScanner(r)
+	for line := 0; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			fileProcessQueue <- taskLine{line: line, uri: scanner.Text()}
+		}
 	}
 
 	close(fileProcessQueue)
@@ -292,47 +414,84 @@ func createDownloadTaskFromFile(client *synoclient.Client, filepath string) {
 	close(errorQueue)
 	errorWg.Wait()
 
-	client.Logout()
+	return scanner.Err()
 }
 
-func readErrors(errorQueue <-chan *synoclient.TaskAddError, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for data := range errorQueue {
-		if data.Err != nil {
-			fmt.Printf("Task %v not added: %v\n", data.Name, data.Err)
-		}
+// taskLine is a URI to be added and its line
+type taskLine struct {
+	line int
+	uri  string
+}
+
+type taskLineError struct {
+	line int
+	data *synoclient.TaskAddError
+}
+
+func printTaskAddError(data *synoclient.TaskAddError) {
+	// duplicates are skipped on request and do not fail the command
+	if data.Err == synoclient.ErrDuplicateTask {
+		printMessage("Task %v not added: %v\n", data.Name, data.Err)
+		return
 	}
+	printErrorf("Task %v not added: %v\n", data.Name, data.Err)
 }
 
-func createDownloadTaskfromURL(client *synoclient.Client, url string) {
+func createDownloadTasksFromURIs(client *synoclient.Client, uris []string, skipDuplicates bool) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
-	var processWg sync.WaitGroup
-	var errorWg sync.WaitGroup
-
-	processWg.Add(1)
-	errorWg.Add(1)
-
-	urlProcessQueue := make(chan string)
-	errorQueue := make(chan *synoclient.TaskAddError, 5)
-
-	go client.CreateDownloadStationTask(urlProcessQueue, errorQueue, &processWg)
-	go readErrors(errorQueue, &errorWg)
+	index, err := newTaskIndex(client, skipDuplicates)
+	if err != nil {
+		printError(err)
+		return
+	}
 
-	urlProcessQueue <- url
+	// uris are passed one per line, so lines are their indexes
+	failures := map[int]error{}
+	err = queueDownloadTasks(client, strings.NewReader(strings.Join(uris, "\n")), index, func(line int, data *synoclient.TaskAddError) {
+		failures[line] = data.Err
+		printTaskAddError(data)
+	})
+	if err != nil {
+		printError(err)
+	}
 
-	close(urlProcessQueue)
-	processWg.Wait()
+	client.Logout()
+	outputTaskAddResults(uris, failures)
+}
 
-	close(errorQueue)
-	errorWg.Wait()
+// taskAddResult is the outcome of adding a URI, status is added, skipped
+// or failed
+type taskAddResult struct {
+	URI    string `json:"uri"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
 
-	client.Logout()
+// outputTaskAddResults writes the outcome of each URI in structured
+// formats, tables are already printed as messages
+func outputTaskAddResults(uris []string, failures map[int]error) {
+	var results []taskAddResult
+	var rows [][]string
+	for i, uri := range uris {
+		if strings.TrimSpace(uri) == "" {
+			continue
+		}
+		result := taskAddResult{URI: uri, Status: "added"}
+		if err := failures[i]; err == synoclient.ErrDuplicateTask {
+			result.Status, result.Error = "skipped", err.Error()
+		} else if err != nil {
+			result.Status, result.Error = "failed", err.Error()
+		}
+		results = append(results, result)
+		rows = append(rows, []string{result.URI, result.Status, result.Error})
+	}
+	printOutput(results, []string{"Uri", "Status", "Error"}, rows, func() {})
 }
 
 func getDownloadTasks(client *synoclient.Client) {
@@ -340,16 +499,17 @@ func getDownloadTasks(client *synoclient.Client) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
-	downloadTasks, e := client.ListDownloadStationTasks()
-	if e != nil {
-		fmt.Println(e)
+	downloadTasks, err := client.ListDownloadStationTasks()
+	if err != nil {
+		printError(err)
+		return
 	}
 
-	if len(downloadTasks) > 0 {
+	if len(downloadTasks) > 0 || structuredOutput() {
 		formatDownloadTasks(downloadTasks)
 	} else {
 		fmt.Println("No download tasks found.")
@@ -363,18 +523,18 @@ func clearFinishedDownloadTasks(client *synoclient.Client) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	tasks, err := client.ListDownloadStationTasks()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	if len(tasks) < 1 {
-		fmt.Println("No tasks found.")
+		printMessage("No tasks found.\n")
 		return
 	}
 
@@ -386,26 +546,18 @@ func clearFinishedDownloadTasks(client *synoclient.Client) {
 	}
 
 	if len(finishedTasks) < 1 {
-		fmt.Println("No finished tasks found.")
+		printMessage("No finished tasks found.\n")
 		return
 	}
 
 	ft := strings.Join(finishedTasks[:], ",")
 	resp, err := client.DeleteDownloadStationTasks(ft)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
-	results := client.GetData(resp).([]interface{})
-	for _, result := range results {
-		r := result.(map[string]interface{})
-		if int(r["error"].(float64)) > 0 {
-			fmt.Printf("Could not delete task id %v (%v).\n", r["id"], synoclient.DsSynoErrors[int(r["error"].(float64))])
-		} else {
-			fmt.Printf("Task %v deleted.\n", r["id"])
-		}
-	}
+	outputTaskResults(printTaskResults(client, resp, "delete", "deleted"))
 
 	// Logout
 	client.Logout()
@@ -415,15 +567,35 @@ func getDownloadTaskInfo(client *synoclient.Client, taskID string) {
 	// Login
 	_, err := client.Login()
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
 
 	task, err := client.GetDownloadStationTask(taskID)
 	if err != nil {
-		fmt.Println(err)
+		printError(err)
 		return
 	}
+	printOutput(task, []string{"Id", "Title", "Type", "Size", "Status", "Username", "Downloaded", "Speed", "Destination"}, [][]string{{
+		task.ID,
+		task.Title,
+		task.Type,
+		strconv.FormatInt(task.Size, 10),
+		task.Status,
+		task.Username,
+		strconv.FormatInt(task.AdditinalTaskInfo.TaskTransfer.SizeDownloaded, 10),
+		strconv.FormatInt(task.AdditinalTaskInfo.TaskTransfer.SpeedDownload, 10),
+		task.AdditinalTaskInfo.TaskDetail.Destination,
+	}}, func() {
+		printDownloadTaskInfo(task)
+	})
+
+	// Logout
+	client.Logout()
+
+}
+
+func printDownloadTaskInfo(task synoclient.DownloadStationTask) {
 	maxlen := len("Size Downloaded: ")
 
 	fmt.Println(strings.Repeat("-", maxlen+len(task.Title)))
@@ -456,10 +628,6 @@ func getDownloadTaskInfo(client *synoclient.Client, taskID string) {
 
 	fmt.Printf(padTitle("Destination:", maxlen))
 	fmt.Println(task.AdditinalTaskInfo.TaskDetail.Destination)
-
-	// Logout
-	client.Logout()
-
 }
 
 func padTitle(title string, maxlen int) string {
@@ -475,24 +643,23 @@ func formatDownloadTasks(dstasks []synoclient.DownloadStationTask) {
 		} else {
 			downloaded = 0
 		}
+		size, progress := ByteCountSI(task.Size), fmt.Sprintf("%v%%", strconv.FormatInt(downloaded, 10))
+		// csv and tsv keep raw bytes
+		if structuredOutput() {
+			size = strconv.FormatInt(task.Size, 10)
+			progress = strconv.FormatInt(task.AdditinalTaskInfo.TaskTransfer.SizeDownloaded, 10)
+		}
 		data = append(data, []string{
 			task.ID,
 			task.Title,
-			//strconv.FormatInt(task.Size, 10),
-			ByteCountSI(task.Size),
+			size,
 			task.Type,
 			task.Status,
-			fmt.Sprintf("%v%%", strconv.FormatInt(downloaded, 10)),
+			progress,
 			task.AdditinalTaskInfo.TaskDetail.Destination,
 		})
 	}
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Id", "Title", "Size", "Type", "Status", "Downloaded", "Destination"})
-
-	for _, v := range data {
-		table.Append(v)
-	}
-	table.Render()
+	printOutput(dstasks, []string{"Id", "Title", "Size", "Type", "Status", "Downloaded", "Destination"}, data, nil)
 }
 
 // ByteCountSI ... https://yourbasic.org/golang/formatting-byte-size-to-human-readable-format/
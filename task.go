@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func listTasks(client *synoclient.Client, args []string) {
+	flags := newFlagSet("task list")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+	getDownloadTasks(client)
+}
+
+func addTasks(client *synoclient.Client, args []string) {
+	flags := newFlagSet("task add")
+	file := flags.String("f", "", "Create download tasks from each line of file")
+	skip := flags.Bool("s", false, "Skip URIs of existing download tasks")
+
+	positional := parseArgs(flags, args)
+	if (*file == "") == (len(positional) == 0) {
+		flags.Usage()
+		return
+	}
+
+	if *file != "" {
+		createDownloadTaskFromFile(client, *file, *skip)
+		return
+	}
+	createDownloadTasksFromURIs(client, positional, *skip)
+}
+
+func showTask(client *synoclient.Client, args []string) {
+	flags := newFlagSet("task info")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		return
+	}
+	getDownloadTaskInfo(client, positional[0])
+}
+
+func removeTasks(client *synoclient.Client, args []string) {
+	if ids, ok := parseTaskIDs("task rm", args); ok {
+		deleteDownloadTasks(client, ids)
+	}
+}
+
+func pauseTasks(client *synoclient.Client, args []string) {
+	if ids, ok := parseTaskIDs("task pause", args); ok {
+		pauseDownloadTasks(client, ids)
+	}
+}
+
+func resumeTasks(client *synoclient.Client, args []string) {
+	if ids, ok := parseTaskIDs("task resume", args); ok {
+		resumeDownloadTasks(client, ids)
+	}
+}
+
+// parseTaskIDs returns the task ids given as arguments or comma separated
+// lists, joined by comma as the Download Station API expects them
+func parseTaskIDs(name string, args []string) (string, bool) {
+	flags := newFlagSet(name)
+
+	var ids []string
+	for _, arg := range parseArgs(flags, args) {
+		for _, id := range strings.Split(arg, ",") {
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		flags.Usage()
+		return "", false
+	}
+	return strings.Join(ids, ","), true
+}
+
+func clearTasks(client *synoclient.Client, args []string) {
+	flags := newFlagSet("task clear")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 0 {
+		flags.Usage()
+		return
+	}
+	clearFinishedDownloadTasks(client)
+}
+
+func moveTask(client *synoclient.Client, args []string) {
+	flags := newFlagSet("task mv")
+
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 {
+		flags.Usage()
+		return
+	}
+	moveDownloadedFile(client, positional[0], positional[1])
+}
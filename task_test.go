@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func TestQueueDownloadTasksLines(t *testing.T) {
+	var mu sync.Mutex
+	var created []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		created = append(created, r.URL.Query().Get("uri"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &synoclient.Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	output := synoclient.Output
+	synoclient.Output = ioutil.Discard
+	defer func() { synoclient.Output = output }()
+
+	const a, b = "http://example.com/a.iso", "http://example.com/b.iso"
+	uris := []string{a, "", b, "bogus", a}
+	failures := map[int]error{}
+	err := queueDownloadTasks(client, strings.NewReader(strings.Join(uris, "\n")), synoclient.NewTaskIndex(nil),
+		func(line int, data *synoclient.TaskAddError) {
+			failures[line] = data.Err
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(created) != 2 {
+		t.Errorf("created tasks %q, want %q and %q", created, a, b)
+	}
+	if failures[3] == nil {
+		t.Errorf("line 3 %q did not fail", uris[3])
+	}
+	// either copy of a may be added first, the other one is skipped
+	if (failures[0] == synoclient.ErrDuplicateTask) == (failures[4] == synoclient.ErrDuplicateTask) {
+		t.Errorf("failures of lines 0 and 4 = %v, %v, want exactly one duplicate", failures[0], failures[4])
+	}
+	if len(failures) != 2 {
+		t.Errorf("failures = %v, want lines 3 and one copy of %v", failures, a)
+	}
+}
+
+func TestRelocateTaskRenamesBack(t *testing.T) {
+	var renames []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Form.Get("api") + "." + r.Form.Get("method") {
+		case "SYNO.FileStation.CreateFolder.create":
+			w.Write([]byte(`{"success":true,"data":{"folders":[{"path":"/video/movies","name":"movies","isdir":true}]}}`))
+		case "SYNO.FileStation.Rename.rename":
+			renames = append(renames, r.Form.Get("path")+" -> "+r.Form.Get("name"))
+			renamed := path.Join(path.Dir(r.Form.Get("path")), r.Form.Get("name"))
+			w.Write([]byte(`{"success":true,"data":{"files":[{"path":"` + renamed + `"}]}}`))
+		case "SYNO.FileStation.CopyMove.start":
+			// destination folder is read-only
+			w.Write([]byte(`{"success":false,"error":{"code":406}}`))
+		default:
+			t.Errorf("unexpected request %v", r.Form)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := &synoclient.Client{Host: u.Host, Scheme: "http", Timeout: 10}
+
+	task := synoclient.DownloadStationTask{ID: "dbid_1", Title: "movie.2020.mkv"}
+	task.AdditinalTaskInfo.TaskDetail.Destination = "downloads"
+	if err := relocateTask(client, task, "/video/movies/Movie (2020).mkv"); err == nil {
+		t.Fatal("relocateTask did not fail")
+	}
+
+	want := []string{
+		"/downloads/movie.2020.mkv -> Movie (2020).mkv",
+		"/downloads/Movie (2020).mkv -> movie.2020.mkv",
+	}
+	if !reflect.DeepEqual(renames, want) {
+		t.Errorf("renames = %q, want %q", renames, want)
+	}
+}
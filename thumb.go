@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/macpoint/synogo/synoclient"
+)
+
+func getThumbnail(client *synoclient.Client, args []string) {
+	flags := newFlagSet("thumb")
+	size := flags.String("size", "small", "Thumbnail size: small, medium, large or original")
+	rotate := flags.Int("rotate", 0, "Rotate by 0-4 times 90 degrees")
+	info := flags.Bool("info", false, "Print the image or video dimensions instead of saving a thumbnail")
+
+	positional := parseArgs(flags, args)
+	if len(positional) < 1 || len(positional) > 2 || (*info && len(positional) != 1) {
+		flags.Usage()
+		return
+	}
+	remote := positional[0]
+
+	// Login
+	_, err := client.Login()
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer client.Logout()
+
+	if *info {
+		config, format, err := client.ImageConfig(remote)
+		if err != nil {
+			printErrorf("Could not read %v: %v\n", remote, err)
+			return
+		}
+		image := imageInfo{Path: remote, Format: format, Width: config.Width, Height: config.Height}
+		printOutput(image, []string{"Path", "Format", "Width", "Height"}, [][]string{
+			{remote, format, strconv.Itoa(config.Width), strconv.Itoa(config.Height)},
+		}, func() {
+			fmt.Printf("%v: %v %vx%v\n", remote, format, config.Width, config.Height)
+		})
+		return
+	}
+
+	thumb, err := client.Thumb(remote, synoclient.ThumbOptions{
+		Size:   synoclient.ThumbSize(*size),
+		Rotate: *rotate,
+	})
+	if err != nil {
+		printErrorf("Could not get thumbnail of %v: %v\n", remote, err)
+		return
+	}
+	defer thumb.Close()
+
+	local := ""
+	if len(positional) == 2 {
+		local = positional[1]
+	} else {
+		ext := ".jpg"
+		switch thumb.ContentType {
+		case "image/png":
+			ext = ".png"
+		case "image/gif":
+			ext = ".gif"
+		}
+		name := path.Base(remote)
+		local = strings.TrimSuffix(name, path.Ext(name)) + "_thumb" + ext
+	}
+
+	f, err := os.Create(local)
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, thumb); err != nil {
+		printErrorf("Could not save thumbnail of %v: %v\n", remote, err)
+		return
+	}
+	printMessage("Thumbnail saved to %v\n", local)
+}
+
+// imageInfo is the format and dimensions of a remote image
+type imageInfo struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}